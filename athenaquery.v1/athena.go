@@ -1,7 +1,9 @@
 package athena
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -13,14 +15,39 @@ const (
 	QueryOptStart  = "startQuery"
 	QueryOptStatus = "queryStatus"
 	QueryOptResult = "queryResult"
+	QueryOptCancel = "cancelQuery"
 )
 
+//stopQueryTimeout bounds the StopQueryExecution call issued after the caller's context is already done
+const stopQueryTimeout = 10 * time.Second
+
+//MaxPageSize is the largest page GetQueryResults returns in one call
+const MaxPageSize = 1000
+
 type AthenaEngine struct {
 	athena         athenaiface.AthenaAPI
 	db             string
 	OutputLocation string
+	WorkGroup      string
+	Catalog        string
 	MaxInterval    int
 	MaxTimeout     int
+	PageSize       int
+	MaxRows        int
+	//DeriveIdempotencyKey derives an idempotency key from RequestParam.QueryID for requests without one
+	DeriveIdempotencyKey bool
+	//DeriveIdempotencyKeyFromContent also keys requests by their SQL, a re-run then gets the first run's results
+	DeriveIdempotencyKeyFromContent bool
+	//KeepRunningOnTimeout leaves the query running in Athena when waiting times out or the context is done
+	KeepRunningOnTimeout bool
+	//Poll decides the wait between status checks, a fixed pollFrequency is used when it is nil
+	Poll PollStrategy
+	//Retry re-submits queries failing with a retryable error in QueryResult, nil disables it
+	Retry *RetryPolicy
+	//Logger receives the engine's logs with credentials redacted, nil drops them
+	Logger Logger
+	//RedactSQL replaces the string literals of logged SQL with '***'
+	RedactSQL bool
 
 	pollFrequency time.Duration
 	//engine.BaseEngine
@@ -32,10 +59,29 @@ func GetInstance(config *Config) (*AthenaEngine, error) {
 	}
 	c := &AthenaEngine{
 		OutputLocation: config.OutputLocation,
+		WorkGroup:      config.WorkGroup,
+		Catalog:        config.Catalog,
 		MaxInterval:    config.MaxInterval,
 		MaxTimeout:     config.MaxTimeout,
+		PageSize:       config.PageSize,
+		MaxRows:        config.MaxRows,
+
+		KeepRunningOnTimeout: config.KeepRunningOnTimeout,
+		DeriveIdempotencyKey: config.DeriveIdempotencyKey,
+		Logger:               config.Logger,
+		RedactSQL:            config.RedactSQL,
+
+		DeriveIdempotencyKeyFromContent: config.DeriveIdempotencyKeyFromContent,
+	}
+	err := c.setupPolling(config)
+	if err != nil {
+		return nil, err
 	}
-	err := c.setupAthenaSession(config)
+	err = c.setupRetry(config)
+	if err != nil {
+		return nil, err
+	}
+	err = c.setupAthenaSession(config)
 	if err != nil {
 		return nil, err
 	}
@@ -44,6 +90,11 @@ func GetInstance(config *Config) (*AthenaEngine, error) {
 
 //Exec for athena query
 func (c *AthenaEngine) Exec(param *RequestParam) (*ResponseData, error) {
+	return c.ExecContext(context.Background(), param)
+}
+
+//ExecContext for athena query, the ctx bounds every Athena call and the polling loop
+func (c *AthenaEngine) ExecContext(ctx context.Context, param *RequestParam) (*ResponseData, error) {
 
 	if param == nil {
 		return nil, nil
@@ -51,7 +102,7 @@ func (c *AthenaEngine) Exec(param *RequestParam) (*ResponseData, error) {
 
 	switch param.QueryOpt {
 	case QueryOptStart:
-		queryID, err := c.ExecuteQuery(param)
+		queryID, err := c.ExecuteQueryContext(ctx, param)
 		if err != nil {
 			return nil, err
 		}
@@ -60,48 +111,228 @@ func (c *AthenaEngine) Exec(param *RequestParam) (*ResponseData, error) {
 		}, nil
 
 	case QueryOptStatus:
-		status, err := c.CheckStatusByQueryID(param.QueryID)
+		qe, err := c.getQueryExecution(ctx, param.QueryID)
 		if err != nil {
 			return nil, err
 		}
+		queued, running, _ := executionTimes(qe)
 		return &ResponseData{
 			QueryID:     param.QueryID,
-			QueryStatus: status,
+			QueryStatus: aws.StringValue(qe.Status.State),
+			QueuedTime:  queued,
+			RunningTime: running,
 		}, nil
 
 	case QueryOptResult:
-		return c.QueryResult(param)
+		return c.QueryResultContext(ctx, param)
+
+	case QueryOptCancel:
+		if err := c.CancelQueryContext(ctx, param.QueryID); err != nil {
+			return nil, err
+		}
+		// StopQueryExecution is asynchronous and also succeeds on a finished query, so report
+		// the state Athena has now rather than assuming CANCELLED; it is left empty if unknown
+		response := &ResponseData{QueryID: param.QueryID}
+		qe, err := c.getQueryExecution(ctx, param.QueryID)
+		if err != nil {
+			c.log().Warn("Athena Query Status After Cancel Failed", LogKeyQueryID, param.QueryID, LogKeyError, err)
+			return response, nil
+		}
+		response.QueryStatus = aws.StringValue(qe.Status.State)
+		return response, nil
 	}
 	return nil, nil
 }
 
+//setupPolling builds the poll strategy from PollFrequency, PollStrategy and MaxInterval
+func (c *AthenaEngine) setupPolling(config *Config) error {
+	frequency, err := ParsePollFrequency(config.PollFrequency)
+	if err != nil {
+		return err
+	}
+	poll, err := NewPollStrategy(config.PollStrategy, frequency, time.Duration(config.MaxInterval)*time.Second)
+	if err != nil {
+		return err
+	}
+	c.pollFrequency, c.Poll = frequency, poll
+	return nil
+}
+
+//setupRetry builds the retry policy from RetryMaxAttempts and RetryBackoff
+func (c *AthenaEngine) setupRetry(config *Config) error {
+	if config.RetryMaxAttempts < 2 {
+		return nil
+	}
+	backoff, err := parseDuration("retry backoff", config.RetryBackoff)
+	if err != nil {
+		return err
+	}
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	maxInterval := time.Duration(config.MaxInterval) * time.Second
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxInterval
+	}
+	c.Retry = &RetryPolicy{
+		MaxAttempts: config.RetryMaxAttempts,
+		Backoff:     ExponentialPoll{Initial: backoff, Max: maxInterval, Multiplier: 2, Jitter: 0.2},
+	}
+	return nil
+}
+
 //For athena connect, it'll only setup the connection config
 func (c *AthenaEngine) Connect() {}
 
-//SetupAthenaSession to set up the session from the config
+//SetupAthenaSession to set up the session from the config. Credentials are resolved in this order:
+//static keys (AccessID and SecretKey, with an optional SessionToken), then a web identity token
+//(WebIdentityTokenFile with Role), then Role, then Region with the SDK default credential chain.
 func (c *AthenaEngine) setupAthenaSession(config *Config) error {
 	if config == nil {
 		return fmt.Errorf("The config is missing")
 	}
+	if err := checkStaticKeys(config); err != nil {
+		return err
+	}
+	sessOpts, err := sessionOptions(config)
+	if err != nil {
+		return err
+	}
+	sessOpts = append(sessOpts, WithLogger(c.log()))
+	if config.AccessID != "" {
+		client, err := c.getAthenaWithKeys(config.Region, config.AccessID, config.SecretKey, config.SessionToken, sessOpts...)
+		if err != nil {
+			return err
+		}
+		c.athena = client
+		return nil
+	}
+	if config.WebIdentityTokenFile != "" {
+		if config.Role == "" {
+			return fmt.Errorf("The Athena Config has WebIdentityTokenFile but Role is missing")
+		}
+		opts, err := roleOptions(config)
+		if err != nil {
+			return err
+		}
+		client, err := c.getAthenaWithWebIdentity(config.Role, config.WebIdentityTokenFile, opts, sessOpts...)
+		if err != nil {
+			return err
+		}
+		c.athena = client
+		return nil
+	}
 	if config.Role != "" {
-		c.athena = c.getAthenaWithRole(config.Role)
+		opts, err := roleOptions(config)
+		if err != nil {
+			return err
+		}
+		client, err := c.getAthenaWithRole(config.Role, opts, sessOpts...)
+		if err != nil {
+			return err
+		}
+		c.athena = client
 		return nil
 	}
 	if config.Region != "" {
-		c.athena = c.getAthenaWithRegion(config.Region)
+		client, err := c.getAthenaWithRegion(config.Region, sessOpts...)
+		if err != nil {
+			return err
+		}
+		c.athena = client
 		return nil
 	}
-	return fmt.Errorf("The Athena Config is insufficient")
+	return fmt.Errorf("The Athena Config is insufficient: set AccessID and SecretKey, Role, or Region")
+}
+
+//checkStaticKeys rejects a partial set of static keys instead of silently falling through to Role or Region
+func checkStaticKeys(config *Config) error {
+	if config.AccessID == "" && config.SecretKey == "" && config.SessionToken == "" {
+		return nil
+	}
+	missing := []string{}
+	if config.AccessID == "" {
+		missing = append(missing, "AccessID")
+	}
+	if config.SecretKey == "" {
+		missing = append(missing, "SecretKey")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("The Athena Config has partial static keys, missing: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (c *AthenaEngine) getAthenaWithKeys(region, accessKey, secretAccessKey, sessionToken string, sessOpts ...SessionOption) (*athena.Athena, error) {
+	session, err := NewSessionWithCredentials(region, accessKey, secretAccessKey, sessionToken, sessOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return athena.New(session, session.AthenaConfig()), nil
+}
+
+func (c *AthenaEngine) getAthenaWithRole(role string, opts RoleOptions, sessOpts ...SessionOption) (*athena.Athena, error) {
+	session, cfg, err := NewSessionWithRoleOptions(role, opts, sessOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return athena.New(session, cfg, session.AthenaConfig()), nil
+}
+
+func (c *AthenaEngine) getAthenaWithWebIdentity(role, tokenFile string, opts RoleOptions, sessOpts ...SessionOption) (*athena.Athena, error) {
+	session, cfg, err := NewSessionWithWebIdentity(role, tokenFile, opts, sessOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return athena.New(session, cfg, session.AthenaConfig()), nil
+}
+
+//roleOptions collects the assume-role settings of the config, the role uses the configured Region
+func roleOptions(config *Config) (RoleOptions, error) {
+	duration, err := parseDuration("role duration", config.RoleDuration)
+	if err != nil {
+		return RoleOptions{}, err
+	}
+	return RoleOptions{
+		Region:           config.Region,
+		ExternalID:       config.RoleExternalID,
+		SessionName:      config.RoleSessionName,
+		Duration:         duration,
+		SourceIdentity:   config.RoleSourceIdentity,
+		MFASerial:        config.RoleMFASerial,
+		MFATokenProvider: config.RoleMFATokenProvider,
+	}, nil
 }
 
-func (c *AthenaEngine) getAthenaWithRole(role string) *athena.Athena {
-	session, cfg := NewSessionWithRole(role)
-	return athena.New(session, cfg)
+//sessionOptions collects the endpoint and HTTP settings of the config
+func sessionOptions(config *Config) ([]SessionOption, error) {
+	timeout, err := parseDuration("http timeout", config.HTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+	opts := []SessionOption{WithEndpoints(Endpoints{
+		Athena:           config.AthenaEndpoint,
+		S3:               config.S3Endpoint,
+		STS:              config.STSEndpoint,
+		S3ForcePathStyle: config.S3ForcePathStyle,
+	})}
+	if config.HTTPClient != nil || timeout > 0 || config.InsecureSkipVerify || config.CABundleFile != "" {
+		opts = append(opts, WithHTTPOptions(HTTPOptions{
+			Timeout:            timeout,
+			InsecureSkipVerify: config.InsecureSkipVerify,
+			CABundleFile:       config.CABundleFile,
+			Client:             config.HTTPClient,
+		}))
+	}
+	return opts, nil
 }
 
-func (c *AthenaEngine) getAthenaWithRegion(region string) *athena.Athena {
-	session, _ := NewSessionWithRegion(region)
-	return athena.New(session)
+func (c *AthenaEngine) getAthenaWithRegion(region string, sessOpts ...SessionOption) (*athena.Athena, error) {
+	session, err := NewSessionWithRegion(region, sessOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return athena.New(session, session.AthenaConfig()), nil
 }
 
 //AthenaQuery is the interface to operate the query
@@ -112,46 +343,185 @@ type AthenaQuery interface {
 	QueryResult(*RequestParam) (*ResponseData, error)
 }
 
-// func (c *AthenaEngine) Config(conf viper.Viper) {
-// 	logger.Infof("Athena Config is loading ... ")
-// 	if c.confMap == nil {
-// 		c.confMap = make(map[string]*Config)
-// 	}
-// 	dbs := config.AthenaConfig(conf)
-// 	for ds := range dbs {
-// 		dbconfig := conf.GetStringMapString(fmt.Sprintf("athena.%s", ds))
-// 		c.confMap[ds] = db.BuildAthenaConfig(dbconfig)
-// 		logger.Infof("Athena-%s config is loaded: %v", ds, c.confMap[ds])
-// 	}
-// }
+//AthenaQueryContext adds the context variants and the cancellation to AthenaQuery
+type AthenaQueryContext interface {
+	AthenaQuery
+	CancelQuery(string) error
+
+	ExecContext(ctx context.Context, param *RequestParam) (*ResponseData, error)
+	ExecuteQueryContext(context.Context, *RequestParam) (queryID string, err error)
+	CheckStatusByQueryIDContext(context.Context, string) (status string, err error)
+	QueryResultContext(context.Context, *RequestParam) (*ResponseData, error)
+	CancelQueryContext(context.Context, string) error
+}
 
 //ExecuteQuery to execute the athena query
 func (c *AthenaEngine) ExecuteQuery(qi *RequestParam) (queryID string, err error) {
-	fmt.Printf("[Executing Athena Query] %s", qi)
+	return c.ExecuteQueryContext(context.Background(), qi)
+}
+
+//ExecuteQueryContext to execute the athena query with a context.
+//The query runs in RequestParam.WorkGroup, or the engine's WorkGroup when it is empty. The
+//OutputLocation may be left empty when the workgroup enforces its own result configuration.
+//RequestParam.Catalog, or else the engine's Catalog, selects a federated or cross-account data
+//catalog; Athena uses AwsDataCatalog when both are empty.
+//RequestParam.IdempotencyKey, or a key derived when DeriveIdempotencyKey or
+//DeriveIdempotencyKeyFromContent is set, is sent as the ClientRequestToken so a repeated
+//start returns the query execution ID of the first one.
+func (c *AthenaEngine) ExecuteQueryContext(ctx context.Context, qi *RequestParam) (queryID string, err error) {
+	return c.startQuery(ctx, qi, 1)
+}
+
+//startQuery submits the query, attempt tells re-submissions of runQuery apart in the ClientRequestToken
+func (c *AthenaEngine) startQuery(ctx context.Context, qi *RequestParam, attempt int) (string, error) {
 	queryInput := &athena.StartQueryExecutionInput{
 		QueryString:           aws.String(qi.SQL),
 		QueryExecutionContext: &athena.QueryExecutionContext{Database: aws.String(qi.DataBase)},
-		ResultConfiguration:   &athena.ResultConfiguration{OutputLocation: aws.String(c.OutputLocation)},
 	}
-	output, err := c.athena.StartQueryExecution(queryInput)
+	catalog := c.Catalog
+	if qi.Catalog != "" {
+		catalog = qi.Catalog
+	}
+	if catalog != "" {
+		queryInput.QueryExecutionContext.Catalog = aws.String(catalog)
+	}
+	if c.OutputLocation != "" {
+		queryInput.ResultConfiguration = &athena.ResultConfiguration{OutputLocation: aws.String(c.OutputLocation)}
+	}
+	workGroup := c.WorkGroup
+	if qi.WorkGroup != "" {
+		workGroup = qi.WorkGroup
+	}
+	if workGroup != "" {
+		queryInput.WorkGroup = aws.String(workGroup)
+	}
+	if len(qi.Params) > 0 {
+		params, err := formatParams(qi.SQL, qi.Params)
+		if err != nil {
+			return "", err
+		}
+		queryInput.ExecutionParameters = aws.StringSlice(params)
+	}
+	if key := c.idempotencyKey(qi, queryInput); key != "" {
+		queryInput.ClientRequestToken = aws.String(idempotencyToken(key, attempt))
+	}
+	c.log().Debug("Executing Athena Query", LogKeySQL, qi.SQL, LogKeyCatalog, catalog, LogKeyDataBase, qi.DataBase, LogKeyWorkGroup, workGroup, LogKeyParamCount, len(qi.Params))
+	output, err := c.athena.StartQueryExecutionWithContext(ctx, queryInput)
 	if err != nil {
-		fmt.Errorf("Athena Query Error: %s", err.Error())
+		c.log().Error("Athena Query Start Failed", LogKeyError, err)
 		return "", err
 	}
-	fmt.Printf("[Finished Athena Query] QueryID: %s", aws.StringValue(output.QueryExecutionId))
+	c.log().Info("Athena Query Started", LogKeyQueryID, aws.StringValue(output.QueryExecutionId))
 	return aws.StringValue(output.QueryExecutionId), nil
 }
 
 //CheckStatusByQueryID to check the query status
 func (c *AthenaEngine) CheckStatusByQueryID(queryID string) (status string, err error) {
-	input := &athena.GetQueryExecutionInput{QueryExecutionId: aws.String(queryID)}
-	output, err := c.athena.GetQueryExecution(input)
+	return c.CheckStatusByQueryIDContext(context.Background(), queryID)
+}
+
+//CheckStatusByQueryIDContext to check the query status with a context
+func (c *AthenaEngine) CheckStatusByQueryIDContext(ctx context.Context, queryID string) (status string, err error) {
+	qe, err := c.getQueryExecution(ctx, queryID)
 	if err != nil {
 		return "", err
 	}
-	status = aws.StringValue(output.QueryExecution.Status.State)
-	c.PrintQueryStatus(output.QueryExecution)
-	return status, nil
+	return aws.StringValue(qe.Status.State), nil
+}
+
+//getQueryExecution fetches the execution of queryID, making sure it carries a status
+func (c *AthenaEngine) getQueryExecution(ctx context.Context, queryID string) (*athena.QueryExecution, error) {
+	input := &athena.GetQueryExecutionInput{QueryExecutionId: aws.String(queryID)}
+	output, err := c.athena.GetQueryExecutionWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if output.QueryExecution == nil || output.QueryExecution.Status == nil {
+		return nil, fmt.Errorf("The Athena Query %s has no status", queryID)
+	}
+	qe := output.QueryExecution
+	c.log().Debug("Athena Query Status", LogKeyQueryID, queryID, LogKeyState, aws.StringValue(qe.Status.State), LogKeyDuration, submittedFor(qe.Status))
+	return qe, nil
+}
+
+//submittedFor is the time since the query was submitted, up to its completion once it is finished
+func submittedFor(status *athena.QueryExecutionStatus) time.Duration {
+	if status.SubmissionDateTime == nil {
+		return 0
+	}
+	end := time.Now()
+	if status.CompletionDateTime != nil {
+		end = *status.CompletionDateTime
+	}
+	return end.Sub(*status.SubmissionDateTime)
+}
+
+//executionTimes reads the queue and engine time Athena reports in the query statistics
+func executionTimes(qe *athena.QueryExecution) (queued, running time.Duration, ok bool) {
+	if qe == nil || qe.Statistics == nil {
+		return 0, 0, false
+	}
+	stats := qe.Statistics
+	if stats.QueryQueueTimeInMillis == nil && stats.EngineExecutionTimeInMillis == nil {
+		return 0, 0, false
+	}
+	queued = time.Duration(aws.Int64Value(stats.QueryQueueTimeInMillis)) * time.Millisecond
+	running = time.Duration(aws.Int64Value(stats.EngineExecutionTimeInMillis)) * time.Millisecond
+	return queued, running, true
+}
+
+//CancelQuery to stop a running query
+func (c *AthenaEngine) CancelQuery(queryID string) error {
+	return c.CancelQueryContext(context.Background(), queryID)
+}
+
+//CancelQueryContext to stop a running query with a context
+func (c *AthenaEngine) CancelQueryContext(ctx context.Context, queryID string) error {
+	if queryID == "" {
+		return fmt.Errorf("The query id to cancel is missing")
+	}
+	input := &athena.StopQueryExecutionInput{QueryExecutionId: aws.String(queryID)}
+	_, err := c.athena.StopQueryExecutionWithContext(ctx, input)
+	return err
+}
+
+//ListDataCatalogs to list the data catalogs queries can select with Catalog
+func (c *AthenaEngine) ListDataCatalogs() ([]*athena.DataCatalogSummary, error) {
+	return c.ListDataCatalogsContext(context.Background())
+}
+
+//ListDataCatalogsContext to list the data catalogs with a context, following every page
+func (c *AthenaEngine) ListDataCatalogsContext(ctx context.Context) ([]*athena.DataCatalogSummary, error) {
+	if c.athena == nil {
+		return nil, fmt.Errorf("The query.AthenaQuery is nil")
+	}
+	catalogs := []*athena.DataCatalogSummary{}
+	input := &athena.ListDataCatalogsInput{}
+	for {
+		output, err := c.athena.ListDataCatalogsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = append(catalogs, output.DataCatalogsSummary...)
+		if aws.StringValue(output.NextToken) == "" {
+			return catalogs, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+//abandonQuery cancels a query the caller stopped waiting for, unless KeepRunningOnTimeout is set,
+//and hands back cause. It uses its own context because the caller's one is usually already done.
+func (c *AthenaEngine) abandonQuery(queryID string, cause error) error {
+	if c.KeepRunningOnTimeout {
+		return cause
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopQueryTimeout)
+	defer cancel()
+	if err := c.CancelQueryContext(ctx, queryID); err != nil {
+		c.log().Warn("Athena Query Cancel Failed", LogKeyQueryID, queryID, LogKeyError, err)
+	}
+	return cause
 }
 
 //PrintQueryStatus to print the query status
@@ -174,89 +544,257 @@ func (c *AthenaEngine) PrintQueryStatus(qe *athena.QueryExecution) string {
 	return fmt.Sprintf("[Athena Query Excution Status] query_id=%s ,query_state=%s, duration=%f", queryID, aws.StringValue(status.State), duration)
 }
 
-//GetQueryResultByQueryID to get rows by queryID
+//GetQueryResultByQueryID to get all rows by queryID, following NextToken until
+//the result set is exhausted or the engine's MaxRows cap is reached
 func (c *AthenaEngine) GetQueryResultByQueryID(queryID string) ([]*athena.ColumnInfo, []*athena.Row, error) {
-	cols, rows := []*athena.ColumnInfo{}, []*athena.Row{}
+	return c.GetQueryResultByQueryIDContext(context.Background(), queryID)
+}
 
-	input := athena.GetQueryResultsInput{QueryExecutionId: aws.String(queryID)}
-	out, err := c.athena.GetQueryResults(&input)
+//GetQueryResultByQueryIDContext to get all rows by queryID with a context
+func (c *AthenaEngine) GetQueryResultByQueryIDContext(ctx context.Context, queryID string) ([]*athena.ColumnInfo, []*athena.Row, error) {
+	cols, rows, _, err := c.getResultByQueryID(ctx, queryID, c.PageSize, c.MaxRows)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	cols, rows = out.ResultSet.ResultSetMetadata.ColumnInfo, out.ResultSet.Rows
-
 	return cols, rows, nil
 }
 
+//QueryResult to start the query, wait for it to finish and fetch its rows
 func (c *AthenaEngine) QueryResult(qi *RequestParam) (*ResponseData, error) {
-	queryID, err := c.ExecuteQuery(qi)
-	if err != nil {
+	return c.QueryResultContext(context.Background(), qi)
+}
+
+//QueryResultContext to start the query, wait for it to finish and fetch its rows with a context.
+//Once a query was started, a failure still returns the ResponseData of the last attempt along with
+//the error, so AttemptedQueryIDs and QueryStatus tell which executions to look at.
+func (c *AthenaEngine) QueryResultContext(ctx context.Context, qi *RequestParam) (*ResponseData, error) {
+	progress, attempted, err := c.runQuery(ctx, qi)
+	if len(attempted) == 0 {
 		return nil, err
 	}
+	response := &ResponseData{
+		QueryID:           attempted[len(attempted)-1],
+		AttemptedQueryIDs: attempted,
+	}
+	if progress != nil {
+		response.QueuedTime, response.RunningTime = progress.queued, progress.running
+		if progress.execution != nil && progress.execution.Status != nil {
+			response.QueryStatus = aws.StringValue(progress.execution.Status.State)
+		}
+	}
+	if err != nil {
+		return response, err
+	}
 
-	if err := c.waitQueryToFinish(queryID); err != nil {
-		return nil, err
+	pageSize, maxRows := c.resultLimits(qi)
+	skipHeader, limit := hasHeaderRow(progress.execution), maxRows
+	if skipHeader && limit > 0 {
+		// the header does not count against MaxRows
+		limit++
+	}
+	cols, rows, truncated, err := c.getResultByQueryID(ctx, response.QueryID, pageSize, limit)
+	if err != nil {
+		return response, err
 	}
+	var header *athena.Row
+	if skipHeader && len(rows) > 0 && isHeaderRow(cols, rows[0]) {
+		header, rows = rows[0], rows[1:]
+	}
+	if maxRows > 0 && len(rows) > maxRows {
+		rows, truncated = rows[:maxRows], true
+	}
+	response.Columns, response.Header, response.Rows, response.Truncated = cols, header, rows, truncated
+	response.QueryStatus = athena.QueryExecutionStateSucceeded
+	return response, nil
+}
 
-	// return adapter.NewRows(adapter.RowsConfig{
-	// 	Athena:  c.athena,
-	// 	QueryID: queryID,
-	// 	// todo add check for ddl queries to not skip header(#10)
-	// 	SkipHeader: true,
-	// })
+//hasHeaderRow reports whether the result of qe starts with a header row. Athena repeats the
+//column names as the first row of a SELECT result but not of DDL or utility results like SHOW
+//or DESCRIBE, so only DML results are checked for one.
+func hasHeaderRow(qe *athena.QueryExecution) bool {
+	return qe != nil && aws.StringValue(qe.StatementType) == athena.StatementTypeDml
+}
 
-	cols, rows, err := c.getResultByQueryID(queryID)
-	if err != nil {
-		return nil, err
+//isHeaderRow reports whether every value of row is the name of its column
+func isHeaderRow(columns []*athena.ColumnInfo, row *athena.Row) bool {
+	if row == nil || len(columns) == 0 || len(row.Data) != len(columns) {
+		return false
+	}
+	for i, column := range columns {
+		if row.Data[i] == nil || aws.StringValue(row.Data[i].VarCharValue) != aws.StringValue(column.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+//queryProgress is what waitQueryToFinish learned about a query while polling it
+type queryProgress struct {
+	execution *athena.QueryExecution
+	queued    time.Duration
+	running   time.Duration
+}
+
+//observe charges the time since the previous poll to the state seen at that poll
+func (p *queryProgress) observe(state string, elapsed time.Duration) {
+	switch state {
+	case athena.QueryExecutionStateQueued:
+		p.queued += elapsed
+	case athena.QueryExecutionStateRunning:
+		p.running += elapsed
+	}
+}
+
+//useStatistics swaps the observed times for the ones Athena reports once the query is finished
+func (p *queryProgress) useStatistics() {
+	if queued, running, ok := executionTimes(p.execution); ok {
+		p.queued, p.running = queued, running
 	}
-	return &ResponseData{
-		QueryID:     queryID,
-		Columns:     cols,
-		Rows:        rows,
-		QueryStatus: athena.QueryExecutionStateSucceeded,
-	}, nil
 }
 
-func (c *AthenaEngine) waitQueryToFinish(queryID string) error {
+//waitQueryToFinish polls the query status until it is finished, timed out or ctx is done.
+//A FAILED, CANCELLED or timed out query ends with a QueryFailedError, QueryCancelledError or QueryTimeoutError.
+//MaxTimeout is measured in wall-clock seconds from submitted, zero or less waits without limit.
+//QUEUED, RUNNING and any state Athena may add later are all waited on the same way;
+//queued and running time are tracked apart and replaced by Athena's statistics when present.
+func (c *AthenaEngine) waitQueryToFinish(ctx context.Context, queryID string, submitted time.Time) (*queryProgress, error) {
 	if c.athena == nil {
-		return fmt.Errorf("The query.AthenaQuery is nil")
+		return nil, fmt.Errorf("The query.AthenaQuery is nil")
 	}
-	runtime, isRunning := 0, true
-	for isRunning {
-		s, e := c.CheckStatusByQueryID(queryID)
+	var deadline time.Time
+	if c.MaxTimeout > 0 {
+		deadline = submitted.Add(time.Duration(c.MaxTimeout) * time.Second)
+	}
+	poll := c.pollStrategy()
+	progress := &queryProgress{}
+	lastState, lastPoll := athena.QueryExecutionStateQueued, submitted
+
+	for attempt := 0; ; attempt++ {
+		qe, e := c.getQueryExecution(ctx, queryID)
 		if e != nil {
-			return e
+			if ctx.Err() != nil {
+				return progress, c.abandonQuery(queryID, ctx.Err())
+			}
+			return progress, e
 		}
-		switch s {
+		now, state := time.Now(), aws.StringValue(qe.Status.State)
+		progress.observe(lastState, now.Sub(lastPoll))
+		progress.execution, lastState, lastPoll = qe, state, now
+
+		switch state {
 		case athena.QueryExecutionStateFailed:
-			return fmt.Errorf("The Athena Query %s is failed", queryID)
+			return progress, c.finishQuery(progress, queryID, now.Sub(submitted), newQueryFailedError(queryID, qe))
 		case athena.QueryExecutionStateCancelled:
-			return fmt.Errorf("The Athena Query %s is cancelled", queryID)
+			return progress, c.finishQuery(progress, queryID, now.Sub(submitted), newQueryCancelledError(queryID, qe))
 		case athena.QueryExecutionStateSucceeded:
-			isRunning = false
-		case athena.QueryExecutionStateRunning:
-			fmt.Printf("running")
-			runtime += c.MaxInterval
-			if runtime > c.MaxTimeout {
-				return fmt.Errorf("The Athena Query %s is timeout", queryID)
+			return progress, c.finishQuery(progress, queryID, now.Sub(submitted), nil)
+		case athena.QueryExecutionStateQueued, athena.QueryExecutionStateRunning:
+			// still pending, keep polling
+		default:
+			c.log().Warn("Athena Query Unknown State", LogKeyQueryID, queryID, LogKeyState, state)
+		}
+
+		wait := poll.Delay(attempt)
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				c.log().Warn("Athena Query Timeout", LogKeyQueryID, queryID, LogKeyState, state, LogKeyDuration, now.Sub(submitted))
+				return progress, c.abandonQuery(queryID, &QueryTimeoutError{
+					QueryID: queryID,
+					State:   state,
+					Elapsed: now.Sub(submitted),
+					Timeout: time.Duration(c.MaxTimeout) * time.Second,
+				})
 			}
-			time.Sleep(time.Duration(3) * time.Second)
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return progress, c.abandonQuery(queryID, ctx.Err())
+		case <-timer.C:
 		}
 	}
+}
+
+//finishQuery records the final statistics and logs the outcome of a query in a final state
+func (c *AthenaEngine) finishQuery(progress *queryProgress, queryID string, elapsed time.Duration, err error) error {
+	progress.useStatistics()
+	state := aws.StringValue(progress.execution.Status.State)
+	if err != nil {
+		c.log().Warn("Athena Query Finished", LogKeyQueryID, queryID, LogKeyState, state, LogKeyDuration, elapsed, LogKeyError, err)
+		return err
+	}
+	c.log().Info("Athena Query Finished", LogKeyQueryID, queryID, LogKeyState, state, LogKeyDuration, elapsed)
 	return nil
 }
 
-func (c *AthenaEngine) getResultByQueryID(queryID string) ([]*athena.ColumnInfo, []*athena.Row, error) {
+//log returns Logger behind the credential redaction, or a no-op logger when it is not set
+func (c *AthenaEngine) log() Logger {
+	return newRedactingLogger(c.Logger, c.RedactSQL)
+}
+
+//pollStrategy returns Poll, or a fixed wait of pollFrequency when Poll is not set
+func (c *AthenaEngine) pollStrategy() PollStrategy {
+	if c.Poll != nil {
+		return c.Poll
+	}
+	frequency := c.pollFrequency
+	if frequency <= 0 {
+		frequency = DefaultPollFrequency
+	}
+	return FixedPoll{Interval: frequency}
+}
+
+//resultLimits picks the page size and row cap for a request, falling back to the engine defaults
+func (c *AthenaEngine) resultLimits(qi *RequestParam) (pageSize, maxRows int) {
+	pageSize, maxRows = c.PageSize, c.MaxRows
+	if qi != nil && qi.PageSize > 0 {
+		pageSize = qi.PageSize
+	}
+	if qi != nil && qi.MaxRows > 0 {
+		maxRows = qi.MaxRows
+	}
+	return pageSize, maxRows
+}
+
+//getResultByQueryID pages through GetQueryResults until NextToken is empty.
+//A maxRows above zero caps the returned rows and reports whether more rows were left behind.
+func (c *AthenaEngine) getResultByQueryID(ctx context.Context, queryID string, pageSize, maxRows int) ([]*athena.ColumnInfo, []*athena.Row, bool, error) {
 	cols, rows := []*athena.ColumnInfo{}, []*athena.Row{}
 
-	input := athena.GetQueryResultsInput{QueryExecutionId: aws.String(queryID)}
-	out, err := c.athena.GetQueryResults(&input)
-	if err != nil {
-		return nil, nil, err
+	input := &athena.GetQueryResultsInput{QueryExecutionId: aws.String(queryID)}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	if pageSize > 0 {
+		input.MaxResults = aws.Int64(int64(pageSize))
 	}
 
-	cols, rows = out.ResultSet.ResultSetMetadata.ColumnInfo, out.ResultSet.Rows
+	for {
+		out, err := c.athena.GetQueryResultsWithContext(ctx, input)
+		if err != nil {
+			return nil, nil, false, err
+		}
 
-	return cols, rows, nil
+		if out.ResultSet != nil {
+			if input.NextToken == nil && out.ResultSet.ResultSetMetadata != nil {
+				cols = out.ResultSet.ResultSetMetadata.ColumnInfo
+			}
+			rows = append(rows, out.ResultSet.Rows...)
+		}
+
+		if maxRows > 0 && len(rows) >= maxRows {
+			truncated := len(rows) > maxRows || aws.StringValue(out.NextToken) != ""
+			return cols, rows[:maxRows], truncated, nil
+		}
+
+		if aws.StringValue(out.NextToken) == "" {
+			return cols, rows, false, nil
+		}
+		input.NextToken = out.NextToken
+	}
 }
@@ -1,12 +1,15 @@
 package athena
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/athena"
 	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
 )
@@ -22,7 +25,8 @@ type MockAthenaClientFail struct {
 
 type MockAthenaClientWait struct {
 	athenaiface.AthenaAPI
-	status string
+	status  string
+	stopped []string
 }
 
 //StartQueryExecution ..
@@ -41,7 +45,10 @@ func (m *MockAthenaClientFail) GetQueryExecution(*athena.GetQueryExecutionInput)
 
 //GetQueryExecution ..
 func (m *MockAthenaClient) GetQueryExecution(*athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
-	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{Status: &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)}}}, nil
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		Status:     &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)},
+		Statistics: &athena.QueryExecutionStatistics{QueryQueueTimeInMillis: aws.Int64(5), EngineExecutionTimeInMillis: aws.Int64(10)},
+	}}, nil
 }
 
 func (m *MockAthenaClientWait) GetQueryExecution(*athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
@@ -74,6 +81,124 @@ func (m *MockAthenaClientFail) GetQueryResults(*athena.GetQueryResultsInput) (*a
 	return nil, fmt.Errorf("GetQueryResults mock error")
 }
 
+//MockAthenaClientPaged serves one single-row page per entry in pages, the first page starts
+//with the header row "id" when header is set
+type MockAthenaClientPaged struct {
+	athenaiface.AthenaAPI
+	pages         []string
+	calls         int
+	header        bool
+	statementType string
+}
+
+func (m *MockAthenaClientPaged) GetQueryResults(input *athena.GetQueryResultsInput) (*athena.GetQueryResultsOutput, error) {
+	idx := 0
+	if input.NextToken != nil {
+		fmt.Sscanf(aws.StringValue(input.NextToken), "page-%d", &idx)
+	}
+	m.calls++
+	out := &athena.GetQueryResultsOutput{
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{
+				ColumnInfo: []*athena.ColumnInfo{&athena.ColumnInfo{Name: aws.String("id"), Type: aws.String("varchar")}},
+			},
+		},
+	}
+	if idx < len(m.pages) {
+		out.ResultSet.Rows = []*athena.Row{&athena.Row{Data: []*athena.Datum{&athena.Datum{VarCharValue: aws.String(m.pages[idx])}}}}
+	}
+	if idx == 0 && m.header {
+		out.ResultSet.Rows = append([]*athena.Row{&athena.Row{Data: []*athena.Datum{&athena.Datum{VarCharValue: aws.String("id")}}}}, out.ResultSet.Rows...)
+	}
+	if idx+1 < len(m.pages) {
+		out.NextToken = aws.String(fmt.Sprintf("page-%d", idx+1))
+	}
+	return out, nil
+}
+
+func (m *MockAthenaClientPaged) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	return m.GetQueryResults(input)
+}
+
+func (m *MockAthenaClientPaged) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("1234-1234")}, nil
+}
+
+func (m *MockAthenaClientPaged) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		QueryExecutionId: input.QueryExecutionId,
+		StatementType:    aws.String(m.statementType),
+		Status:           &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)},
+	}}, nil
+}
+
+func rowValues(rows []*athena.Row) []string {
+	values := []string{}
+	for _, r := range rows {
+		values = append(values, aws.StringValue(r.Data[0].VarCharValue))
+	}
+	return values
+}
+
+func (m *MockAthenaClient) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	return m.StartQueryExecution(input)
+}
+func (m *MockAthenaClient) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	return m.GetQueryExecution(input)
+}
+func (m *MockAthenaClient) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	return m.GetQueryResults(input)
+}
+
+func (m *MockAthenaClientFail) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	return m.StartQueryExecution(input)
+}
+func (m *MockAthenaClientFail) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	return m.GetQueryExecution(input)
+}
+func (m *MockAthenaClientFail) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	return m.GetQueryResults(input)
+}
+
+func (m *MockAthenaClientWait) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.GetQueryExecution(input)
+}
+
+func (m *MockAthenaClientWait) StopQueryExecutionWithContext(ctx aws.Context, input *athena.StopQueryExecutionInput, opts ...request.Option) (*athena.StopQueryExecutionOutput, error) {
+	m.stopped = append(m.stopped, aws.StringValue(input.QueryExecutionId))
+	return &athena.StopQueryExecutionOutput{}, nil
+}
+
+func (m *MockAthenaClient) StopQueryExecutionWithContext(ctx aws.Context, input *athena.StopQueryExecutionInput, opts ...request.Option) (*athena.StopQueryExecutionOutput, error) {
+	return &athena.StopQueryExecutionOutput{}, nil
+}
+
+func (m *MockAthenaClientFail) StopQueryExecutionWithContext(ctx aws.Context, input *athena.StopQueryExecutionInput, opts ...request.Option) (*athena.StopQueryExecutionOutput, error) {
+	return nil, fmt.Errorf("StopQueryExecution mock error")
+}
+
+//MockAthenaClientSequence reports the states one after another, repeating the last one
+type MockAthenaClientSequence struct {
+	athenaiface.AthenaAPI
+	states []string
+	calls  int
+}
+
+func (m *MockAthenaClientSequence) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	state := m.states[len(m.states)-1]
+	if m.calls < len(m.states) {
+		state = m.states[m.calls]
+	}
+	m.calls++
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		QueryExecutionId: input.QueryExecutionId,
+		Status:           &athena.QueryExecutionStatus{State: aws.String(state)},
+	}}, nil
+}
+
 var mockAthenaClient = &MockAthenaClient{}
 var mockAthenaClientFail = &MockAthenaClientFail{}
 
@@ -107,7 +232,7 @@ func TestAthenaEngine_Exec(t *testing.T) {
 					DataBase: "viewership",
 				},
 			},
-			want:           &ResponseData{QueryID: "12345-12345", QueryStatus: "SUCCEEDED"},
+			want:           &ResponseData{QueryID: "12345-12345", QueryStatus: "SUCCEEDED", QueuedTime: 5 * time.Millisecond, RunningTime: 10 * time.Millisecond},
 			wantFailClient: false,
 		},
 		{name: "testQueryResult",
@@ -118,7 +243,7 @@ func TestAthenaEngine_Exec(t *testing.T) {
 					DataBase: "index",
 				},
 			},
-			want: &ResponseData{QueryID: "12345-12345", QueryStatus: "SUCCEEDED", Columns: []*athena.ColumnInfo{
+			want: &ResponseData{QueryID: "12345-12345", QueryStatus: "SUCCEEDED", QueuedTime: 5 * time.Millisecond, RunningTime: 10 * time.Millisecond, AttemptedQueryIDs: []string{"12345-12345"}, Columns: []*athena.ColumnInfo{
 				&athena.ColumnInfo{
 					Name:       aws.String("max_job_id"),
 					SchemaName: aws.String("job_id"),
@@ -136,6 +261,17 @@ func TestAthenaEngine_Exec(t *testing.T) {
 			},
 			wantFailClient: false,
 		},
+		{name: "testCancelQuery",
+			args: args{
+				param: &RequestParam{
+					QueryID:  "12345-12345",
+					QueryOpt: "cancelQuery",
+				},
+			},
+			// the mock query already SUCCEEDED, stopping it does not make it CANCELLED
+			want:           &ResponseData{QueryID: "12345-12345", QueryStatus: "SUCCEEDED"},
+			wantFailClient: false,
+		},
 		{name: "testStartQuery-fail",
 			args: args{
 				param: &RequestParam{
@@ -158,6 +294,16 @@ func TestAthenaEngine_Exec(t *testing.T) {
 			wantFailClient: true,
 			wantErr:        true,
 		},
+		{name: "testCancelQuery-fail",
+			args: args{
+				param: &RequestParam{
+					QueryID:  "12345-12345",
+					QueryOpt: "cancelQuery",
+				},
+			},
+			wantFailClient: true,
+			wantErr:        true,
+		},
 		{name: "testQueryResult-fail",
 			args: args{
 				param: &RequestParam{
@@ -194,6 +340,42 @@ func TestAthenaEngine_Exec(t *testing.T) {
 	}
 }
 
+//MockAthenaClientStopOnly stops queries but fails to report their status
+type MockAthenaClientStopOnly struct {
+	MockAthenaClientFail
+}
+
+func (m *MockAthenaClientStopOnly) StopQueryExecutionWithContext(ctx aws.Context, input *athena.StopQueryExecutionInput, opts ...request.Option) (*athena.StopQueryExecutionOutput, error) {
+	return &athena.StopQueryExecutionOutput{}, nil
+}
+
+func TestAthenaEngine_ExecCancel(t *testing.T) {
+	tests := []struct {
+		name       string
+		athena     athenaiface.AthenaAPI
+		wantStatus string
+		wantErr    bool
+	}{
+		{name: "t-cancelled", athena: &MockAthenaClientWait{status: athena.QueryExecutionStateCancelled}, wantStatus: athena.QueryExecutionStateCancelled},
+		{name: "t-still-running", athena: &MockAthenaClientWait{status: athena.QueryExecutionStateRunning}, wantStatus: athena.QueryExecutionStateRunning},
+		{name: "t-status-unknown", athena: &MockAthenaClientStopOnly{}},
+		{name: "t-stop-fails", athena: &MockAthenaClientFail{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AthenaEngine{athena: tt.athena}
+			got, err := c.Exec(&RequestParam{QueryID: "12345-12345", QueryOpt: QueryOptCancel})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AthenaEngine.Exec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (got.QueryID != "12345-12345" || got.QueryStatus != tt.wantStatus) {
+				t.Errorf("AthenaEngine.Exec() = %+v, want status %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
 func TestGetInstance(t *testing.T) {
 	type args struct {
 		config *Config
@@ -211,6 +393,14 @@ func TestGetInstance(t *testing.T) {
 		}}, wantErr: false,
 		},
 		{name: "t-2", args: args{config: nil}, wantErr: true},
+		{name: "t-3", args: args{config: &Config{Region: "us-east-1", PollStrategy: "linear"}}, wantErr: true},
+		{name: "t-4", args: args{config: &Config{Region: "us-east-1", PollFrequency: "soon"}}, wantErr: true},
+		{name: "t-5", args: args{config: &Config{Region: "us-east-1", PollFrequency: "500ms", PollStrategy: "exponential", MaxInterval: 10}}, wantErr: false},
+		{name: "t-6", args: args{config: &Config{Region: "us-east-1", RetryMaxAttempts: 3, RetryBackoff: "later"}}, wantErr: true},
+		{name: "t-7", args: args{config: &Config{Region: "us-east-1", RetryMaxAttempts: 3, RetryBackoff: "2s"}}, wantErr: false},
+		{name: "t-8-mfa-no-provider", args: args{config: &Config{Region: "us-east-1", Role: "arn:xx:xx", RoleMFASerial: "mfa"}}, wantErr: true},
+		{name: "t-9-mfa", args: args{config: &Config{Region: "us-east-1", Role: "arn:xx:xx", RoleMFASerial: "mfa",
+			RoleMFATokenProvider: func() (string, error) { return "123456", nil }}}, wantErr: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -273,6 +463,21 @@ func TestAthenaEngine_setupAthenaSession(t *testing.T) {
 		{name: "t-1", args: args{}, wantErr: true},
 		{name: "t-2", args: args{config: &Config{Region: "us-east-1"}}, wantErr: false},
 		{name: "t-3", args: args{config: &Config{Role: "role-test"}}, wantErr: false},
+		{name: "t-4-keys", args: args{config: &Config{Region: "us-east-1", AccessID: "id", SecretKey: "secret"}}, wantErr: false},
+		{name: "t-5-keys-token", args: args{config: &Config{AccessID: "id", SecretKey: "secret", SessionToken: "token", Role: "role-test"}}, wantErr: false},
+		{name: "t-6-missing-secret", args: args{config: &Config{Region: "us-east-1", AccessID: "id"}}, wantErr: true},
+		{name: "t-7-missing-id", args: args{config: &Config{Region: "us-east-1", SecretKey: "secret"}}, wantErr: true},
+		{name: "t-8-token-only", args: args{config: &Config{Role: "role-test", SessionToken: "token"}}, wantErr: true},
+		{name: "t-9-empty", args: args{config: &Config{}}, wantErr: true},
+		{name: "t-10-role-options", args: args{config: &Config{Role: "role-test", Region: "eu-west-1", RoleExternalID: "ext", RoleSessionName: "lambda", RoleDuration: "1h", RoleSourceIdentity: "me"}}, wantErr: false},
+		{name: "t-11-role-bad-duration", args: args{config: &Config{Role: "role-test", RoleDuration: "long"}}, wantErr: true},
+		{name: "t-12-role-mfa-no-provider", args: args{config: &Config{Role: "role-test", RoleMFASerial: "arn:aws:iam::123456789012:mfa/me"}}, wantErr: true},
+		{name: "t-13-web-identity-no-role", args: args{config: &Config{Region: "us-east-1", WebIdentityTokenFile: "token"}}, wantErr: true},
+		{name: "t-14-web-identity-missing-file", args: args{config: &Config{Role: "role-test", WebIdentityTokenFile: "/does/not/exist"}}, wantErr: true},
+		{name: "t-15-web-identity-external-id", args: args{config: &Config{Role: "role-test", WebIdentityTokenFile: "token", RoleExternalID: "ext"}}, wantErr: true},
+		{name: "t-15-endpoints", args: args{config: &Config{Region: "us-east-1", AthenaEndpoint: "http://localhost:4566", STSEndpoint: "http://localhost:4566", HTTPTimeout: "30s"}}, wantErr: false},
+		{name: "t-16-bad-http-timeout", args: args{config: &Config{Region: "us-east-1", HTTPTimeout: "soon"}}, wantErr: true},
+		{name: "t-17-missing-ca-bundle", args: args{config: &Config{Region: "us-east-1", CABundleFile: "/does/not/exist.pem"}}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -291,6 +496,27 @@ func TestAthenaEngine_setupAthenaSession(t *testing.T) {
 	}
 }
 
+func TestCheckStaticKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{name: "t-none", config: &Config{Region: "us-east-1"}},
+		{name: "t-complete", config: &Config{AccessID: "id", SecretKey: "secret", SessionToken: "token"}},
+		{name: "t-missing-secret", config: &Config{AccessID: "id"}, wantErr: "The Athena Config has partial static keys, missing: SecretKey"},
+		{name: "t-token-only", config: &Config{SessionToken: "token"}, wantErr: "The Athena Config has partial static keys, missing: AccessID, SecretKey"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStaticKeys(tt.config)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("checkStaticKeys() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestAthenaEngine_getAthenaWithRole(t *testing.T) {
 	type fields struct {
 		athena         athenaiface.AthenaAPI
@@ -321,13 +547,39 @@ func TestAthenaEngine_getAthenaWithRole(t *testing.T) {
 				MaxTimeout:     tt.fields.MaxTimeout,
 				pollFrequency:  tt.fields.pollFrequency,
 			}
-			if got := c.getAthenaWithRole(tt.args.role); !reflect.DeepEqual(got, tt.want) {
+			if got, _ := c.getAthenaWithRole(tt.args.role, RoleOptions{}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AthenaEngine.getAthenaWithRole() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestRoleOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		want    RoleOptions
+		wantErr bool
+	}{
+		{name: "t-empty", config: &Config{Role: "r"}, want: RoleOptions{}},
+		{name: "t-all", config: &Config{Role: "r", Region: "eu-west-1", RoleExternalID: "ext", RoleSessionName: "lambda", RoleDuration: "900", RoleSourceIdentity: "me", RoleMFASerial: "mfa"},
+			want: RoleOptions{Region: "eu-west-1", ExternalID: "ext", SessionName: "lambda", Duration: 15 * time.Minute, SourceIdentity: "me", MFASerial: "mfa"}},
+		{name: "t-bad-duration", config: &Config{Role: "r", RoleDuration: "-1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := roleOptions(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("roleOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("roleOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAthenaEngine_getAthenaWithRegion(t *testing.T) {
 	type fields struct {
 		athena         athenaiface.AthenaAPI
@@ -358,7 +610,7 @@ func TestAthenaEngine_getAthenaWithRegion(t *testing.T) {
 				MaxTimeout:     tt.fields.MaxTimeout,
 				pollFrequency:  tt.fields.pollFrequency,
 			}
-			if got := c.getAthenaWithRegion(tt.args.region); !reflect.DeepEqual(got, tt.want) {
+			if got, _ := c.getAthenaWithRegion(tt.args.region); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("AthenaEngine.getAthenaWithRegion() = %v, want %v", got, tt.want)
 			}
 		})
@@ -408,6 +660,130 @@ func TestAthenaEngine_ExecuteQuery(t *testing.T) {
 	}
 }
 
+func TestAthenaEngine_ExecuteQueryWorkGroup(t *testing.T) {
+	tests := []struct {
+		name           string
+		engine         *AthenaEngine
+		param          *RequestParam
+		wantWorkGroup  *string
+		wantResultConf *athena.ResultConfiguration
+	}{
+		{name: "t-defaults", engine: &AthenaEngine{OutputLocation: "s3://bucket/out"}, param: &RequestParam{SQL: "SELECT 1"},
+			wantResultConf: &athena.ResultConfiguration{OutputLocation: aws.String("s3://bucket/out")}},
+		{name: "t-engine-workgroup", engine: &AthenaEngine{WorkGroup: "team-a"}, param: &RequestParam{SQL: "SELECT 1"},
+			wantWorkGroup: aws.String("team-a")},
+		{name: "t-request-workgroup", engine: &AthenaEngine{WorkGroup: "team-a", OutputLocation: "s3://bucket/out"}, param: &RequestParam{SQL: "SELECT 1", WorkGroup: "team-b"},
+			wantWorkGroup: aws.String("team-b"), wantResultConf: &athena.ResultConfiguration{OutputLocation: aws.String("s3://bucket/out")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientStart{}
+			tt.engine.athena = mock
+			if _, err := tt.engine.ExecuteQueryContext(context.Background(), tt.param); err != nil {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(mock.input.WorkGroup, tt.wantWorkGroup) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() workgroup = %v, want %v", aws.StringValue(mock.input.WorkGroup), aws.StringValue(tt.wantWorkGroup))
+			}
+			if !reflect.DeepEqual(mock.input.ResultConfiguration, tt.wantResultConf) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() result configuration = %v, want %v", mock.input.ResultConfiguration, tt.wantResultConf)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_ExecuteQueryCatalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		engine      *AthenaEngine
+		param       *RequestParam
+		wantContext *athena.QueryExecutionContext
+	}{
+		{name: "t-default", engine: &AthenaEngine{}, param: &RequestParam{SQL: "SELECT 1", DataBase: "db"},
+			wantContext: &athena.QueryExecutionContext{Database: aws.String("db")}},
+		{name: "t-engine-catalog", engine: &AthenaEngine{Catalog: "dynamo"}, param: &RequestParam{SQL: "SELECT 1", DataBase: "default"},
+			wantContext: &athena.QueryExecutionContext{Catalog: aws.String("dynamo"), Database: aws.String("default")}},
+		{name: "t-request-catalog", engine: &AthenaEngine{Catalog: "dynamo"}, param: &RequestParam{SQL: "SELECT 1", DataBase: "sales", Catalog: "partner-glue"},
+			wantContext: &athena.QueryExecutionContext{Catalog: aws.String("partner-glue"), Database: aws.String("sales")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientStart{}
+			tt.engine.athena = mock
+			if _, err := tt.engine.ExecuteQueryContext(context.Background(), tt.param); err != nil {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(mock.input.QueryExecutionContext, tt.wantContext) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() context = %v, want %v", mock.input.QueryExecutionContext, tt.wantContext)
+			}
+		})
+	}
+}
+
+//MockAthenaClientCatalogs serves pages of data catalogs, failing when pages is empty
+type MockAthenaClientCatalogs struct {
+	athenaiface.AthenaAPI
+	pages  [][]string
+	tokens []string
+}
+
+func (m *MockAthenaClientCatalogs) ListDataCatalogsWithContext(ctx aws.Context, input *athena.ListDataCatalogsInput, opts ...request.Option) (*athena.ListDataCatalogsOutput, error) {
+	if len(m.pages) == 0 {
+		return nil, fmt.Errorf("ListDataCatalogs mock error")
+	}
+	m.tokens = append(m.tokens, aws.StringValue(input.NextToken))
+	page := len(m.tokens) - 1
+	output := &athena.ListDataCatalogsOutput{}
+	for _, name := range m.pages[page] {
+		output.DataCatalogsSummary = append(output.DataCatalogsSummary, &athena.DataCatalogSummary{CatalogName: aws.String(name), Type: aws.String("GLUE")})
+	}
+	if page+1 < len(m.pages) {
+		output.NextToken = aws.String(fmt.Sprintf("token-%d", page+1))
+	}
+	return output, nil
+}
+
+func TestAthenaEngine_ListDataCatalogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       *MockAthenaClientCatalogs
+		want       []string
+		wantTokens []string
+		wantErr    bool
+	}{
+		{name: "t-one-page", mock: &MockAthenaClientCatalogs{pages: [][]string{{"AwsDataCatalog", "dynamo"}}},
+			want: []string{"AwsDataCatalog", "dynamo"}, wantTokens: []string{""}},
+		{name: "t-pages", mock: &MockAthenaClientCatalogs{pages: [][]string{{"AwsDataCatalog"}, {"dynamo"}, {"partner-glue"}}},
+			want: []string{"AwsDataCatalog", "dynamo", "partner-glue"}, wantTokens: []string{"", "token-1", "token-2"}},
+		{name: "t-error", mock: &MockAthenaClientCatalogs{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AthenaEngine{athena: tt.mock}
+			got, err := c.ListDataCatalogs()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AthenaEngine.ListDataCatalogs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			names := []string{}
+			for _, catalog := range got {
+				names = append(names, aws.StringValue(catalog.CatalogName))
+			}
+			if !reflect.DeepEqual(names, tt.want) || !reflect.DeepEqual(tt.mock.tokens, tt.wantTokens) {
+				t.Errorf("AthenaEngine.ListDataCatalogs() = %v with tokens %v, want %v with tokens %v", names, tt.mock.tokens, tt.want, tt.wantTokens)
+			}
+		})
+	}
+	if _, err := (&AthenaEngine{}).ListDataCatalogs(); err == nil {
+		t.Errorf("AthenaEngine.ListDataCatalogs() without client should fail")
+	}
+}
+
 func TestAthenaEngine_CheckStatusByQueryID(t *testing.T) {
 	type args struct {
 		queryID string
@@ -535,19 +911,36 @@ func TestAthenaEngine_QueryResult(t *testing.T) {
 		OutputLocation string
 		MaxInterval    int
 		MaxTimeout     int
+		MaxRows        int
 		pollFrequency  time.Duration
 	}
 	type args struct {
 		qi *RequestParam
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    *ResponseData
-		wantErr bool
+		name          string
+		fields        fields
+		args          args
+		wantHeader    bool
+		wantRows      []string
+		wantTruncated bool
+		wantErr       bool
 	}{
-	// TODO: Add test cases.
+		{name: "t-select", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDml, header: true, pages: []string{"a", "b"}}},
+			args: args{qi: &RequestParam{SQL: "SELECT id FROM t"}}, wantHeader: true, wantRows: []string{"a", "b"}},
+		{name: "t-select-empty", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDml, header: true}},
+			args: args{qi: &RequestParam{SQL: "SELECT id FROM t WHERE false"}}, wantHeader: true, wantRows: []string{}},
+		{name: "t-select-max-rows", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDml, header: true, pages: []string{"a", "b", "c"}}, MaxRows: 2},
+			args: args{qi: &RequestParam{SQL: "SELECT id FROM t"}}, wantHeader: true, wantRows: []string{"a", "b"}, wantTruncated: true},
+		{name: "t-select-max-rows-all", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDml, header: true, pages: []string{"a", "b"}}, MaxRows: 2},
+			args: args{qi: &RequestParam{SQL: "SELECT id FROM t"}}, wantHeader: true, wantRows: []string{"a", "b"}},
+		{name: "t-dml-without-header", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDml, pages: []string{"a", "b", "c"}}, MaxRows: 2},
+			args: args{qi: &RequestParam{SQL: "INSERT INTO t SELECT 1"}}, wantRows: []string{"a", "b"}, wantTruncated: true},
+		{name: "t-ddl", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeDdl, header: true, pages: []string{"a"}}},
+			args: args{qi: &RequestParam{SQL: "SHOW TABLES"}}, wantRows: []string{"id", "a"}},
+		{name: "t-utility", fields: fields{athena: &MockAthenaClientPaged{statementType: athena.StatementTypeUtility, pages: []string{"a"}}},
+			args: args{qi: &RequestParam{SQL: "DESCRIBE t"}}, wantRows: []string{"a"}},
+		{name: "t-fail", fields: fields{athena: &MockAthenaClientFail{}}, args: args{qi: &RequestParam{SQL: "SELECT 1"}}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -557,6 +950,7 @@ func TestAthenaEngine_QueryResult(t *testing.T) {
 				OutputLocation: tt.fields.OutputLocation,
 				MaxInterval:    tt.fields.MaxInterval,
 				MaxTimeout:     tt.fields.MaxTimeout,
+				MaxRows:        tt.fields.MaxRows,
 				pollFrequency:  tt.fields.pollFrequency,
 			}
 			got, err := c.QueryResult(tt.args.qi)
@@ -564,8 +958,17 @@ func TestAthenaEngine_QueryResult(t *testing.T) {
 				t.Errorf("AthenaEngine.QueryResult() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("AthenaEngine.QueryResult() = %v, want %v", got, tt.want)
+			if tt.wantErr {
+				return
+			}
+			if (got.Header != nil) != tt.wantHeader {
+				t.Errorf("AthenaEngine.QueryResult() header = %v, wantHeader %v", got.Header, tt.wantHeader)
+			}
+			if rows := rowValues(got.Rows); !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("AthenaEngine.QueryResult() rows = %v, want %v", rows, tt.wantRows)
+			}
+			if got.Truncated != tt.wantTruncated {
+				t.Errorf("AthenaEngine.QueryResult() truncated = %v, want %v", got.Truncated, tt.wantTruncated)
 			}
 		})
 	}
@@ -576,69 +979,197 @@ func TestAthenaEngine_waitQueryToFinish(t *testing.T) {
 		queryID string
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
+		name        string
+		args        args
+		keepRunning bool
+		wantErr     bool
+		wantStopped []string
 	}{
-		{name: "t1", args: args{queryID: "1234-1234"}, wantErr: true},
+		{name: "t1", args: args{queryID: "1234-1234"}, wantErr: true, wantStopped: []string{"1234-1234"}},
+		{name: "t2-keep-running", args: args{queryID: "1234-1234"}, keepRunning: true, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientWait{status: "RUNNING"}
 			c := &AthenaEngine{
-				athena:      &MockAthenaClientWait{status: "RUNNING"},
-				MaxTimeout:  1,
-				MaxInterval: 1,
+				athena:               mock,
+				MaxTimeout:           1,
+				MaxInterval:          1,
+				KeepRunningOnTimeout: tt.keepRunning,
 			}
 
-			if err := c.waitQueryToFinish(tt.args.queryID); (err != nil) != tt.wantErr {
+			if _, err := c.waitQueryToFinish(context.Background(), tt.args.queryID, time.Now()); (err != nil) != tt.wantErr {
 				t.Errorf("AthenaEngine.waitQueryToFinish() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if !reflect.DeepEqual(mock.stopped, tt.wantStopped) {
+				t.Errorf("AthenaEngine.waitQueryToFinish() stopped = %v, want %v", mock.stopped, tt.wantStopped)
+			}
 		})
 	}
 }
 
-func TestAthenaEngine_getResultByQueryID(t *testing.T) {
-	type fields struct {
-		athena         athenaiface.AthenaAPI
-		db             string
-		OutputLocation string
-		MaxInterval    int
-		MaxTimeout     int
-		pollFrequency  time.Duration
+func TestAthenaEngine_waitQueryToFinishPoll(t *testing.T) {
+	mock := &MockAthenaClientSequence{states: []string{"RUNNING", "RUNNING", "SUCCEEDED"}}
+	c := &AthenaEngine{
+		athena:     mock,
+		MaxTimeout: 60,
+		Poll:       FixedPoll{Interval: time.Millisecond},
 	}
-	type args struct {
-		queryID string
+	if _, err := c.waitQueryToFinish(context.Background(), "1234-1234", time.Now()); err != nil {
+		t.Errorf("AthenaEngine.waitQueryToFinish() error = %v", err)
 	}
+	if mock.calls != 3 {
+		t.Errorf("AthenaEngine.waitQueryToFinish() calls = %v, want 3", mock.calls)
+	}
+}
+
+func TestAthenaEngine_waitQueryToFinishStates(t *testing.T) {
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []*athena.ColumnInfo
-		want1   []*athena.Row
-		wantErr bool
+		name        string
+		states      []string
+		wantErr     bool
+		wantQueued  bool
+		wantRunning bool
 	}{
-	// TODO: Add test cases.
+		{name: "t-queued-running", states: []string{"QUEUED", "QUEUED", "RUNNING", "RUNNING", "SUCCEEDED"}, wantQueued: true, wantRunning: true},
+		{name: "t-queued-only", states: []string{"QUEUED", "QUEUED", "SUCCEEDED"}, wantQueued: true},
+		{name: "t-unknown-state", states: []string{"PENDING_SOMETHING", "RUNNING", "SUCCEEDED"}, wantQueued: true, wantRunning: true},
+		{name: "t-queued-failed", states: []string{"QUEUED", "FAILED"}, wantErr: true, wantQueued: true},
+		{name: "t-queued-timeout", states: []string{"QUEUED"}, wantErr: true, wantQueued: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientSequence{states: tt.states}
 			c := &AthenaEngine{
-				athena:         tt.fields.athena,
-				db:             tt.fields.db,
-				OutputLocation: tt.fields.OutputLocation,
-				MaxInterval:    tt.fields.MaxInterval,
-				MaxTimeout:     tt.fields.MaxTimeout,
-				pollFrequency:  tt.fields.pollFrequency,
+				athena:               mock,
+				MaxTimeout:           1,
+				KeepRunningOnTimeout: true,
+				Poll:                 FixedPoll{Interval: 5 * time.Millisecond},
 			}
-			got, got1, err := c.getResultByQueryID(tt.args.queryID)
+			progress, err := c.waitQueryToFinish(context.Background(), "1234-1234", time.Now())
 			if (err != nil) != tt.wantErr {
-				t.Errorf("AthenaEngine.getResultByQueryID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("AthenaEngine.waitQueryToFinish() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("AthenaEngine.getResultByQueryID() got = %v, want %v", got, tt.want)
+			if (progress.queued > 0) != tt.wantQueued {
+				t.Errorf("AthenaEngine.waitQueryToFinish() queued = %v, want queued %v", progress.queued, tt.wantQueued)
 			}
-			if !reflect.DeepEqual(got1, tt.want1) {
-				t.Errorf("AthenaEngine.getResultByQueryID() got1 = %v, want %v", got1, tt.want1)
+			if (progress.running > 0) != tt.wantRunning {
+				t.Errorf("AthenaEngine.waitQueryToFinish() running = %v, want running %v", progress.running, tt.wantRunning)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_waitQueryToFinishWallClock(t *testing.T) {
+	mock := &MockAthenaClientWait{status: "RUNNING"}
+	c := &AthenaEngine{
+		athena:     mock,
+		MaxTimeout: 5,
+		Poll:       FixedPoll{Interval: time.Millisecond},
+	}
+	// submitted long enough ago that the deadline has already passed
+	if _, err := c.waitQueryToFinish(context.Background(), "1234-1234", time.Now().Add(-10*time.Second)); err == nil {
+		t.Errorf("AthenaEngine.waitQueryToFinish() should time out against the submission time")
+	}
+}
+
+func TestAthenaEngine_waitQueryToFinishContext(t *testing.T) {
+	mock := &MockAthenaClientWait{status: "RUNNING"}
+	c := &AthenaEngine{
+		athena:      mock,
+		MaxTimeout:  60,
+		MaxInterval: 1,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.waitQueryToFinish(ctx, "1234-1234", time.Now())
+	if err != context.DeadlineExceeded {
+		t.Errorf("AthenaEngine.waitQueryToFinish() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("AthenaEngine.waitQueryToFinish() took %v after the context was done", elapsed)
+	}
+	if !reflect.DeepEqual(mock.stopped, []string{"1234-1234"}) {
+		t.Errorf("AthenaEngine.waitQueryToFinish() stopped = %v", mock.stopped)
+	}
+}
+
+func TestAthenaEngine_ExecContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &AthenaEngine{
+		athena: &MockAthenaClientWait{status: "RUNNING"},
+	}
+	if _, err := c.ExecContext(ctx, &RequestParam{QueryID: "1234-1234", QueryOpt: QueryOptStatus}); err != context.Canceled {
+		t.Errorf("AthenaEngine.ExecContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAthenaEngine_getResultByQueryID(t *testing.T) {
+	type args struct {
+		pageSize int
+		maxRows  int
+	}
+	tests := []struct {
+		name          string
+		pages         []string
+		args          args
+		want          []string
+		wantTruncated bool
+		wantCalls     int
+	}{
+		{name: "t-single-page", pages: []string{"a"}, want: []string{"a"}, wantCalls: 1},
+		{name: "t-all-pages", pages: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}, wantCalls: 3},
+		{name: "t-capped", pages: []string{"a", "b", "c"}, args: args{maxRows: 2}, want: []string{"a", "b"}, wantTruncated: true, wantCalls: 2},
+		{name: "t-cap-equals-total", pages: []string{"a", "b"}, args: args{maxRows: 2}, want: []string{"a", "b"}, wantCalls: 2},
+		{name: "t-page-size", pages: []string{"a", "b"}, args: args{pageSize: 5000}, want: []string{"a", "b"}, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientPaged{pages: tt.pages}
+			c := &AthenaEngine{
+				athena: mock,
+			}
+			cols, rows, truncated, err := c.getResultByQueryID(context.Background(), "1234-1234", tt.args.pageSize, tt.args.maxRows)
+			if err != nil {
+				t.Errorf("AthenaEngine.getResultByQueryID() error = %v", err)
+				return
+			}
+			if len(cols) != 1 {
+				t.Errorf("AthenaEngine.getResultByQueryID() cols = %v", cols)
+			}
+			if got := rowValues(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AthenaEngine.getResultByQueryID() rows = %v, want %v", got, tt.want)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("AthenaEngine.getResultByQueryID() truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if mock.calls != tt.wantCalls {
+				t.Errorf("AthenaEngine.getResultByQueryID() calls = %v, want %v", mock.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_resultLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		param        *RequestParam
+		wantPageSize int
+		wantMaxRows  int
+	}{
+		{name: "t-defaults", param: nil, wantPageSize: 100, wantMaxRows: 10},
+		{name: "t-override", param: &RequestParam{PageSize: 50, MaxRows: 5}, wantPageSize: 50, wantMaxRows: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AthenaEngine{PageSize: 100, MaxRows: 10}
+			pageSize, maxRows := c.resultLimits(tt.param)
+			if pageSize != tt.wantPageSize || maxRows != tt.wantMaxRows {
+				t.Errorf("AthenaEngine.resultLimits() = %v, %v, want %v, %v", pageSize, maxRows, tt.wantPageSize, tt.wantMaxRows)
 			}
 		})
 	}
@@ -649,29 +1180,140 @@ func TestBuildAthenaConfig(t *testing.T) {
 		conf map[string]string
 	}
 	tests := []struct {
-		name string
-		args args
-		want *Config
+		name         string
+		args         args
+		want         *Config
+		wantErr      bool
+		wantProblems int
 	}{
 		{name: "t-1", args: args{
 			conf: map[string]string{
-				"maxInterval":     "1",
-				"maxTimeout":      "1",
-				"output_location": "test",
-				"region":          "testRegion",
-				"role":            "testRole",
+				"maxInterval":          "1",
+				"maxTimeout":           "1",
+				"pageSize":             "500",
+				"maxRows":              "10000",
+				"keepRunningOnTimeout": "true",
+				"retryMaxAttempts":     "3",
+				"retry_backoff":        "2s",
+				"deriveIdempotencyKey": "true",
+				"output_location":      "s3://bucket/test",
+				"workgroup":            "team-a",
+				"catalog":              "dynamo",
+				"poll_frequency":       "2s",
+				"poll_strategy":        "exponential",
+				"region":               "testRegion",
+				"role":                 "testRole",
+				"role_external_id":     "ext",
+				"role_session_name":    "lambda",
+				"role_duration":        "3600",
+				"role_source_identity": "me",
+				"role_mfa_serial":      "mfa",
+
+				"web_identity_token_file": "/var/run/secrets/token",
+
+				"derive_idempotency_key_from_content": "true",
+
+				"athena_endpoint":      "http://localhost:4566",
+				"s3_endpoint":          "http://localhost:4566",
+				"sts_endpoint":         "http://localhost:4566",
+				"s3_force_path_style":  "true",
+				"http_timeout":         "30s",
+				"insecure_skip_verify": "true",
+				"ca_bundle_file":       "/etc/ssl/proxy.pem",
+				"redact_sql":           "true",
 			},
 		}, want: &Config{
 			Region:         "testRegion",
 			Role:           "testRole",
 			MaxTimeout:     1,
 			MaxInterval:    1,
-			OutputLocation: "test",
+			PageSize:       500,
+			MaxRows:        10000,
+			OutputLocation: "s3://bucket/test",
+			WorkGroup:      "team-a",
+			Catalog:        "dynamo",
+			PollFrequency:  "2s",
+			PollStrategy:   "exponential",
+
+			KeepRunningOnTimeout: true,
+			RetryMaxAttempts:     3,
+			RetryBackoff:         "2s",
+			DeriveIdempotencyKey: true,
+
+			DeriveIdempotencyKeyFromContent: true,
+
+			RoleExternalID:     "ext",
+			RoleSessionName:    "lambda",
+			RoleDuration:       "3600",
+			RoleSourceIdentity: "me",
+			RoleMFASerial:      "mfa",
+
+			WebIdentityTokenFile: "/var/run/secrets/token",
+
+			AthenaEndpoint:     "http://localhost:4566",
+			S3Endpoint:         "http://localhost:4566",
+			STSEndpoint:        "http://localhost:4566",
+			S3ForcePathStyle:   true,
+			HTTPTimeout:        "30s",
+			InsecureSkipVerify: true,
+			CABundleFile:       "/etc/ssl/proxy.pem",
+
+			RedactSQL: true,
+		}},
+		{name: "t-2-empty", args: args{conf: map[string]string{}}, wantErr: true},
+		{name: "t-3-key-styles", args: args{conf: map[string]string{
+			"output_location": "s3://bucket",
+			"outputLocation":  "s3://bucket",
+			"max_timeout":     "60",
+			"pageSize":        "100",
+			"workGroup":       "team-a",
+			"accessID":        "id",
+			"secretKey":       "secret",
+			"roleMFASerial":   "mfa",
+		}}, want: &Config{
+			OutputLocation: "s3://bucket",
+			WorkGroup:      "team-a",
+			MaxTimeout:     60,
+			PageSize:       100,
+			AccessID:       "id",
+			SecretKey:      "secret",
+			RoleMFASerial:  "mfa",
 		}},
+		{name: "t-4-conflicting-keys", args: args{conf: map[string]string{
+			"region":          "us-east-1",
+			"output_location": "s3://bucket/a",
+			"outputLocation":  "s3://bucket/b",
+		}}, wantErr: true, wantProblems: 1},
+		{name: "t-5-all-problems", args: args{conf: map[string]string{
+			"output_location": "bucket/results",
+			"maxTimeout":      "-1",
+			"maxInterval":     "ten",
+			"page_size":       "5000",
+			"poll_frequency":  "soon",
+			"poll_strategy":   "linear",
+			"redact_sql":      "maybe",
+			"access_id":       "id",
+		}}, wantErr: true, wantProblems: 8},
+		{name: "t-6-bad-bucket", args: args{conf: map[string]string{
+			"region":          "us-east-1",
+			"output_location": "s3://Bad_Bucket/results",
+		}}, wantErr: true, wantProblems: 1},
+		{name: "t-7-no-output", args: args{conf: map[string]string{"region": "us-east-1"}}, wantErr: true, wantProblems: 1},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := BuildAthenaConfig(tt.args.conf); !reflect.DeepEqual(got, tt.want) {
+			got, err := BuildAthenaConfig(tt.args.conf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildAthenaConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantProblems > 0 {
+				var configErr *ConfigError
+				if !errors.As(err, &configErr) || len(configErr.Problems) != tt.wantProblems {
+					t.Errorf("BuildAthenaConfig() error = %v, want %d problems", err, tt.wantProblems)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("BuildAthenaConfig() = %v, want %v", got, tt.want)
 			}
 		})
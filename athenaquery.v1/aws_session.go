@@ -1,50 +1,293 @@
 package athena
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 type AwsSession struct {
 	*session.Session
+
+	endpoints  Endpoints
+	httpClient *http.Client
+	logger     Logger
 }
 
-//NewSessionWithKeys for aws opt
-func NewSessionWithKeys(region, accessKey, secretAccessKey string) (*AwsSession, error) {
-	fmt.Printf("[New AWS Session with keys] region=%s, accessKey=%s, secretAccessKey=%s", region, accessKey, secretAccessKey)
-	config := aws.NewConfig().WithRegion(region).
-		WithCredentials(credentials.NewStaticCredentials(accessKey, secretAccessKey, ""))
+//Endpoints override the default service endpoints, e.g. for LocalStack or VPC endpoints.
+//Empty fields keep the SDK default for that service.
+type Endpoints struct {
+	Athena string
+	S3     string
+	STS    string
+	//S3ForcePathStyle is usually needed by S3 stand-ins that don't serve virtual-hosted buckets
+	S3ForcePathStyle bool
+}
+
+//HTTPOptions tune the HTTP client shared by every service client of the session
+type HTTPOptions struct {
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+	//CABundleFile is a PEM file of extra root certificates, e.g. for a TLS-intercepting proxy
+	CABundleFile string
+	//Client replaces the whole client, the other fields are ignored when it is set
+	Client *http.Client
+}
+
+//SessionOption is accepted by every session constructor in this file that returns an error,
+//since an option such as WithHTTPOptions can fail
+type SessionOption func(*AwsSession) error
+
+//WithEndpoints for aws opt, overriding the service endpoints
+func WithEndpoints(endpoints Endpoints) SessionOption {
+	return func(s *AwsSession) error {
+		s.endpoints = endpoints
+		return nil
+	}
+}
+
+//WithHTTPOptions for aws opt, building the HTTP client from the options
+func WithHTTPOptions(opts HTTPOptions) SessionOption {
+	return func(s *AwsSession) error {
+		client, err := newHTTPClient(opts)
+		if err != nil {
+			return err
+		}
+		s.httpClient = client
+		return nil
+	}
+}
+
+//WithLogger for aws opt, logging the session setup to logger with credentials redacted
+func WithLogger(logger Logger) SessionOption {
+	return func(s *AwsSession) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+//AthenaConfig returns the client config for athena.New, carrying the Athena endpoint override
+func (s *AwsSession) AthenaConfig() *aws.Config {
+	config := aws.NewConfig()
+	if s.endpoints.Athena != "" {
+		config = config.WithEndpoint(s.endpoints.Athena)
+	}
+	return config
+}
+
+//S3Config returns the client config for s3.New, carrying the S3 endpoint override
+func (s *AwsSession) S3Config() *aws.Config {
+	config := aws.NewConfig()
+	if s.endpoints.S3 != "" {
+		config = config.WithEndpoint(s.endpoints.S3)
+	}
+	if s.endpoints.S3ForcePathStyle {
+		config = config.WithS3ForcePathStyle(true)
+	}
+	return config
+}
+
+//STSConfig returns the client config for sts.New, carrying the STS endpoint override
+func (s *AwsSession) STSConfig() *aws.Config {
+	config := aws.NewConfig()
+	if s.endpoints.STS != "" {
+		config = config.WithEndpoint(s.endpoints.STS)
+	}
+	return config
+}
+
+//newAwsSession applies opts and creates the session from config
+func newAwsSession(config *aws.Config, opts []SessionOption) (*AwsSession, error) {
+	s := &AwsSession{logger: NopLogger{}}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.httpClient != nil {
+		config = config.WithHTTPClient(s.httpClient)
+	}
 	sess, err := session.NewSession(config)
 	if err != nil {
 		return nil, err
 	}
-	return &AwsSession{Session: sess}, nil
+	s.Session = sess
+	s.logger = newRedactingLogger(s.logger, false)
+	return s, nil
+}
+
+func newHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	if opts.Client != nil {
+		return opts.Client, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CABundleFile != "" {
+		pem, err := os.ReadFile(opts.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("The CA bundle file is not readable: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("The CA bundle file %s has no PEM certificate", opts.CABundleFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: opts.Timeout}, nil
+}
+
+//NewSessionWithKeys for aws opt
+func NewSessionWithKeys(region, accessKey, secretAccessKey string, opts ...SessionOption) (*AwsSession, error) {
+	return NewSessionWithCredentials(region, accessKey, secretAccessKey, "", opts...)
+}
+
+//NewSessionWithCredentials for aws opt with static keys and an optional session token,
+//an empty region is left to the environment
+func NewSessionWithCredentials(region, accessKey, secretAccessKey, sessionToken string, opts ...SessionOption) (*AwsSession, error) {
+	config := aws.NewConfig().WithCredentials(credentials.NewStaticCredentials(accessKey, secretAccessKey, sessionToken))
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+	sess, err := newAwsSession(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	sess.logger.Info("New AWS Session with keys", LogKeyRegion, region, LogKeyAccessID, accessKey)
+	return sess, nil
 }
 
-//NewSession for aws opt
+//NewSession for aws opt, it panics when the session can't be created. Use NewSessionWithOptions
+//to pass SessionOptions.
 func NewSession() *AwsSession {
-	sess := session.Must(session.NewSession())
-	return &AwsSession{Session: sess}
+	sess, err := newAwsSession(aws.NewConfig(), nil)
+	if err != nil {
+		panic(err)
+	}
+	return sess
+}
+
+//NewSessionWithOptions for aws opt, the region and credentials are left to the environment
+func NewSessionWithOptions(opts ...SessionOption) (*AwsSession, error) {
+	return newAwsSession(aws.NewConfig(), opts)
 }
 
-//NewSessionWithRole for aws opt
+//NewSessionWithRole for aws opt. Use NewSessionWithRoleOptions to pass SessionOptions.
 func NewSessionWithRole(role string) (*AwsSession, *aws.Config) {
-	fmt.Printf("[New AWS Session with Role1] role=%s", role)
 	sess := NewSession()
-	creds := stscreds.NewCredentials(sess, role)
+	sess.logger.Info("New AWS Session with role", LogKeyRole, role)
+	creds := stscreds.NewCredentials(sess, role, func(p *stscreds.AssumeRoleProvider) {
+		p.Client = sts.New(sess, sess.STSConfig())
+	})
 	return sess, &aws.Config{Credentials: creds}
 }
 
-//NewSessionWithRegion for aws opt
-func NewSessionWithRegion(region string) (*AwsSession, error) {
-	config := aws.NewConfig().WithRegion(region)
-	sess, err := session.NewSession(config)
+//RoleOptions tune the session of an assumed role
+type RoleOptions struct {
+	//Region is used by both the STS calls and the clients built from the session, empty leaves it to the environment
+	Region         string
+	ExternalID     string
+	SessionName    string
+	Duration       time.Duration
+	SourceIdentity string
+	MFASerial      string
+	//MFATokenProvider returns the current MFA code, it is required with MFASerial
+	MFATokenProvider func() (string, error)
+}
+
+//NewSessionWithRoleOptions for aws opt, assuming role with the given options
+func NewSessionWithRoleOptions(role string, opts RoleOptions, sessOpts ...SessionOption) (*AwsSession, *aws.Config, error) {
+	if opts.MFASerial != "" && opts.MFATokenProvider == nil {
+		return nil, nil, fmt.Errorf("The role MFA serial %s is set without an MFA token provider", opts.MFASerial)
+	}
+	config := aws.NewConfig()
+	if opts.Region != "" {
+		config = config.WithRegion(opts.Region)
+	}
+	sess, err := newAwsSession(config, sessOpts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &AwsSession{Session: sess}, nil
+	creds := stscreds.NewCredentials(sess, role, func(p *stscreds.AssumeRoleProvider) {
+		p.Client = sts.New(sess, sess.STSConfig())
+		if opts.ExternalID != "" {
+			p.ExternalID = aws.String(opts.ExternalID)
+		}
+		if opts.SessionName != "" {
+			p.RoleSessionName = opts.SessionName
+		}
+		if opts.Duration > 0 {
+			p.Duration = opts.Duration
+		}
+		if opts.SourceIdentity != "" {
+			p.SourceIdentity = aws.String(opts.SourceIdentity)
+		}
+		if opts.MFASerial != "" {
+			p.SerialNumber = aws.String(opts.MFASerial)
+			p.TokenProvider = opts.MFATokenProvider
+		}
+	})
+	cfg := &aws.Config{Credentials: creds}
+	if opts.Region != "" {
+		cfg.Region = aws.String(opts.Region)
+	}
+	return sess, cfg, nil
+}
+
+//NewSessionWithWebIdentity for aws opt, exchanging the web identity token in tokenFile (as projected
+//into EKS pods) for credentials of role. opts.Region, opts.SessionName and opts.Duration apply, the
+//other options are rejected since AssumeRoleWithWebIdentity has no such parameters.
+func NewSessionWithWebIdentity(role, tokenFile string, opts RoleOptions, sessOpts ...SessionOption) (*AwsSession, *aws.Config, error) {
+	unsupported := []string{}
+	if opts.ExternalID != "" {
+		unsupported = append(unsupported, "ExternalID")
+	}
+	if opts.SourceIdentity != "" {
+		unsupported = append(unsupported, "SourceIdentity")
+	}
+	if opts.MFASerial != "" {
+		unsupported = append(unsupported, "MFASerial")
+	}
+	if len(unsupported) > 0 {
+		return nil, nil, fmt.Errorf("The web identity role does not support: %s", strings.Join(unsupported, ", "))
+	}
+	if _, err := os.Stat(tokenFile); err != nil {
+		return nil, nil, fmt.Errorf("The web identity token file is not readable: %v", err)
+	}
+	config := aws.NewConfig()
+	if opts.Region != "" {
+		config = config.WithRegion(opts.Region)
+	}
+	sess, err := newAwsSession(config, sessOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := stscreds.NewWebIdentityRoleProvider(sts.New(sess, sess.STSConfig()), role, opts.SessionName, tokenFile)
+	if opts.Duration > 0 {
+		provider.Duration = opts.Duration
+	}
+	cfg := &aws.Config{Credentials: credentials.NewCredentials(provider)}
+	if opts.Region != "" {
+		cfg.Region = aws.String(opts.Region)
+	}
+	return sess, cfg, nil
+}
+
+//NewSessionWithRegion for aws opt
+func NewSessionWithRegion(region string, opts ...SessionOption) (*AwsSession, error) {
+	config := aws.NewConfig().WithRegion(region)
+	return newAwsSession(config, opts)
 }
@@ -1,7 +1,13 @@
 package athena
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 )
@@ -30,6 +36,40 @@ func TestNewSessionWithKeys(t *testing.T) {
 	}
 }
 
+func TestNewSessionWithCredentials(t *testing.T) {
+	type args struct {
+		region          string
+		accessKey       string
+		secretAccessKey string
+		sessionToken    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{name: "t-1", args: args{region: "us-east-1", accessKey: "key", secretAccessKey: "secret", sessionToken: "token"}, wantErr: false},
+		{name: "t-2", args: args{accessKey: "key", secretAccessKey: "secret"}, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSessionWithCredentials(tt.args.region, tt.args.accessKey, tt.args.secretAccessKey, tt.args.sessionToken)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSessionWithCredentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			creds, err := got.Config.Credentials.Get()
+			if err != nil {
+				t.Errorf("NewSessionWithCredentials() credentials error = %v", err)
+				return
+			}
+			if creds.AccessKeyID != tt.args.accessKey || creds.SecretAccessKey != tt.args.secretAccessKey || creds.SessionToken != tt.args.sessionToken {
+				t.Errorf("NewSessionWithCredentials() credentials = %v", creds)
+			}
+		})
+	}
+}
+
 func TestNewSession(t *testing.T) {
 	tests := []struct {
 		name string
@@ -45,6 +85,30 @@ func TestNewSession(t *testing.T) {
 	}
 }
 
+func TestNewSessionWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []SessionOption
+		wantErr bool
+	}{
+		{name: "t-1"},
+		{name: "t-2-endpoints", opts: []SessionOption{WithEndpoints(Endpoints{STS: "http://localhost:4566"})}},
+		{name: "t-3-missing-ca-bundle", opts: []SessionOption{WithHTTPOptions(HTTPOptions{CABundleFile: "/does/not/exist"})}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSessionWithOptions(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSessionWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got == nil {
+				t.Errorf("NewSessionWithOptions() = %v should not be nil", got)
+			}
+		})
+	}
+}
+
 func TestNewSessionWithRole(t *testing.T) {
 	type args struct {
 		role string
@@ -70,6 +134,118 @@ func TestNewSessionWithRole(t *testing.T) {
 	}
 }
 
+func TestNewSessionWithRoleOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       RoleOptions
+		wantRegion string
+		wantErr    bool
+	}{
+		{name: "t-default-region", opts: RoleOptions{}},
+		{name: "t-region", opts: RoleOptions{Region: "eu-west-1", ExternalID: "ext", SessionName: "lambda", Duration: time.Hour, SourceIdentity: "me"}, wantRegion: "eu-west-1"},
+		{name: "t-mfa", opts: RoleOptions{MFASerial: "mfa", MFATokenProvider: func() (string, error) { return "123456", nil }}},
+		{name: "t-mfa-no-provider", opts: RoleOptions{MFASerial: "mfa"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess, cfg, err := NewSessionWithRoleOptions("arn:xx:xx", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSessionWithRoleOptions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.Credentials == nil {
+				t.Errorf("NewSessionWithRoleOptions() has no credentials")
+			}
+			if aws.StringValue(cfg.Region) != tt.wantRegion {
+				t.Errorf("NewSessionWithRoleOptions() config region = %v, want %v", aws.StringValue(cfg.Region), tt.wantRegion)
+			}
+			if tt.wantRegion != "" && aws.StringValue(sess.Config.Region) != tt.wantRegion {
+				t.Errorf("NewSessionWithRoleOptions() session region = %v, want %v", aws.StringValue(sess.Config.Region), tt.wantRegion)
+			}
+		})
+	}
+}
+
+//newSTSStandIn answers AssumeRoleWithWebIdentity for role when called with token and duration seconds
+func newSTSStandIn(t *testing.T, role, token, duration string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("STS stand-in ParseForm() error = %v", err)
+		}
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("RoleArn") != role || r.Form.Get("WebIdentityToken") != token ||
+			r.Form.Get("DurationSeconds") != duration {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>denied</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDWEB</AccessKeyId>
+      <SecretAccessKey>web-secret</SecretAccessKey>
+      <SessionToken>web-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+  <ResponseMetadata><RequestId>1</RequestId></ResponseMetadata>
+</AssumeRoleWithWebIdentityResponse>`)
+	}))
+}
+
+func TestNewSessionWithWebIdentity(t *testing.T) {
+	role := "arn:aws:iam::123456789012:role/athena"
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("projected-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		role         string
+		tokenFile    string
+		opts         RoleOptions
+		wantDuration string
+		wantErr      bool
+		wantKey      string
+		wantDeny     bool
+	}{
+		{name: "t-1", role: role, tokenFile: tokenFile, wantKey: "AKIDWEB"},
+		{name: "t-2-wrong-role", role: "arn:aws:iam::123456789012:role/other", tokenFile: tokenFile, wantDeny: true},
+		{name: "t-3-missing-file", role: role, tokenFile: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+		{name: "t-4-duration", role: role, tokenFile: tokenFile, opts: RoleOptions{Duration: time.Hour}, wantDuration: "3600", wantKey: "AKIDWEB"},
+		{name: "t-5-external-id", role: role, tokenFile: tokenFile, opts: RoleOptions{ExternalID: "ext"}, wantErr: true},
+		{name: "t-6-source-identity", role: role, tokenFile: tokenFile, opts: RoleOptions{SourceIdentity: "me"}, wantErr: true},
+		{name: "t-7-mfa", role: role, tokenFile: tokenFile, opts: RoleOptions{MFASerial: "mfa"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSTSStandIn(t, role, "projected-token", tt.wantDuration)
+			defer srv.Close()
+			tt.opts.Region, tt.opts.SessionName = "us-east-1", "test"
+			_, cfg, err := NewSessionWithWebIdentity(tt.role, tt.tokenFile, tt.opts, WithEndpoints(Endpoints{STS: srv.URL}))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSessionWithWebIdentity() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			creds, err := cfg.Credentials.Get()
+			if (err != nil) != tt.wantDeny {
+				t.Errorf("NewSessionWithWebIdentity() credentials error = %v, wantDeny %v", err, tt.wantDeny)
+				return
+			}
+			if creds.AccessKeyID != tt.wantKey {
+				t.Errorf("NewSessionWithWebIdentity() access key = %v, want %v", creds.AccessKeyID, tt.wantKey)
+			}
+		})
+	}
+}
+
 func TestNewSessionWithRegion(t *testing.T) {
 	type args struct {
 		region string
@@ -93,3 +269,94 @@ func TestNewSessionWithRegion(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []SessionOption
+		wantAthena string
+		wantS3     string
+		wantSTS    string
+		wantPath   bool
+	}{
+		{name: "t-default"},
+		{name: "t-localstack", opts: []SessionOption{WithEndpoints(Endpoints{Athena: "http://localhost:4566", S3: "http://localhost:4566", STS: "http://localhost:4566", S3ForcePathStyle: true})},
+			wantAthena: "http://localhost:4566", wantS3: "http://localhost:4566", wantSTS: "http://localhost:4566", wantPath: true},
+		{name: "t-athena-only", opts: []SessionOption{WithEndpoints(Endpoints{Athena: "https://vpce-1.athena.us-east-1.vpce.amazonaws.com"})},
+			wantAthena: "https://vpce-1.athena.us-east-1.vpce.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess, err := NewSessionWithRegion("us-east-1", tt.opts...)
+			if err != nil {
+				t.Errorf("NewSessionWithRegion() error = %v", err)
+				return
+			}
+			if got := aws.StringValue(sess.AthenaConfig().Endpoint); got != tt.wantAthena {
+				t.Errorf("AwsSession.AthenaConfig() endpoint = %v, want %v", got, tt.wantAthena)
+			}
+			if got := aws.StringValue(sess.S3Config().Endpoint); got != tt.wantS3 {
+				t.Errorf("AwsSession.S3Config() endpoint = %v, want %v", got, tt.wantS3)
+			}
+			if got := aws.BoolValue(sess.S3Config().S3ForcePathStyle); got != tt.wantPath {
+				t.Errorf("AwsSession.S3Config() path style = %v, want %v", got, tt.wantPath)
+			}
+			if got := aws.StringValue(sess.STSConfig().Endpoint); got != tt.wantSTS {
+				t.Errorf("AwsSession.STSConfig() endpoint = %v, want %v", got, tt.wantSTS)
+			}
+		})
+	}
+}
+
+func TestWithHTTPOptions(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	custom := &http.Client{}
+
+	tests := []struct {
+		name        string
+		opts        HTTPOptions
+		wantErr     bool
+		wantClient  *http.Client
+		wantTimeout time.Duration
+	}{
+		{name: "t-timeout", opts: HTTPOptions{Timeout: 5 * time.Second}, wantTimeout: 5 * time.Second},
+		{name: "t-custom-client", opts: HTTPOptions{Client: custom, Timeout: time.Second}, wantClient: custom},
+		{name: "t-missing-ca-bundle", opts: HTTPOptions{CABundleFile: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "t-invalid-ca-bundle", opts: HTTPOptions{CABundleFile: notPEM}, wantErr: true},
+		{name: "t-insecure", opts: HTTPOptions{InsecureSkipVerify: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess, err := NewSessionWithRegion("us-east-1", WithHTTPOptions(tt.opts))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSessionWithRegion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			client := sess.Config.HTTPClient
+			if client == nil {
+				t.Errorf("NewSessionWithRegion() has no HTTP client")
+				return
+			}
+			if tt.wantClient != nil && client != tt.wantClient {
+				t.Errorf("NewSessionWithRegion() HTTP client = %v, want %v", client, tt.wantClient)
+			}
+			if tt.wantClient == nil && client.Timeout != tt.wantTimeout {
+				t.Errorf("NewSessionWithRegion() HTTP timeout = %v, want %v", client.Timeout, tt.wantTimeout)
+			}
+			if tt.opts.InsecureSkipVerify {
+				if _, err := client.Get(srv.URL); err != nil {
+					t.Errorf("HTTP client with InsecureSkipVerify error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package athena
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//configKeyAliases maps normalized keys that differ from the canonical snake_case key
+var configKeyAliases = map[string]string{
+	"work_group": "workgroup",
+}
+
+//s3Bucket matches a valid S3 bucket name
+var s3Bucket = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+//NormalizeConfigKey turns a camelCase config key into the canonical snake_case one,
+//e.g. "maxTimeout" into "max_timeout" and "roleMFASerial" into "role_mfa_serial"
+func NormalizeConfigKey(key string) string {
+	runes := []rune(strings.TrimSpace(key))
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '-' || r == ' ' {
+			r = '_'
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	normalized := b.String()
+	if alias, ok := configKeyAliases[normalized]; ok {
+		return alias
+	}
+	return normalized
+}
+
+//configReader reads typed values out of a config map, collecting every problem on the way
+type configReader struct {
+	values   map[string]string
+	problems []error
+}
+
+func newConfigReader(conf map[string]string) *configReader {
+	r := &configReader{values: make(map[string]string, len(conf))}
+	keys := map[string]string{}
+	for key, value := range conf {
+		normalized := NormalizeConfigKey(key)
+		if other, ok := keys[normalized]; ok && r.values[normalized] != value {
+			first, second := other, key
+			if first > second {
+				first, second = second, first
+			}
+			r.problems = append(r.problems, fmt.Errorf("The config keys %s and %s set different values", first, second))
+		}
+		keys[normalized] = key
+		r.values[normalized] = value
+	}
+	return r
+}
+
+func (r *configReader) str(key string) string {
+	return strings.TrimSpace(r.values[key])
+}
+
+func (r *configReader) int(key string) int {
+	value := r.str(key)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Errorf("The config %s %q is not an integer", key, value))
+	}
+	return n
+}
+
+func (r *configReader) bool(key string) bool {
+	value := r.str(key)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		r.problems = append(r.problems, fmt.Errorf("The config %s %q is not a boolean", key, value))
+	}
+	return b
+}
+
+//Validate checks the config the way GetInstance would use it and reports every problem found
+//in one *ConfigError, or returns nil
+func (c *Config) Validate() error {
+	problems := []error{}
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if c.OutputLocation == "" && c.WorkGroup == "" {
+		check(fmt.Errorf("The OutputLocation is required unless a WorkGroup provides it"))
+	}
+	if c.OutputLocation != "" {
+		check(validateS3URI(c.OutputLocation))
+	}
+	if c.AccessID == "" && c.Role == "" && c.Region == "" {
+		check(fmt.Errorf("The Athena Config is insufficient: set AccessID and SecretKey, Role, or Region"))
+	}
+	check(checkStaticKeys(c))
+	if c.WebIdentityTokenFile != "" && c.Role == "" {
+		check(fmt.Errorf("The Athena Config has WebIdentityTokenFile but Role is missing"))
+	}
+
+	_, err := ParsePollFrequency(c.PollFrequency)
+	check(err)
+	_, err = NewPollStrategy(c.PollStrategy, 0, 0)
+	check(err)
+	_, err = parseDuration("retry backoff", c.RetryBackoff)
+	check(err)
+	_, err = parseDuration("role duration", c.RoleDuration)
+	check(err)
+	_, err = parseDuration("http timeout", c.HTTPTimeout)
+	check(err)
+
+	if c.MaxTimeout < 0 {
+		check(fmt.Errorf("The MaxTimeout %d is negative", c.MaxTimeout))
+	}
+	if c.MaxInterval < 0 {
+		check(fmt.Errorf("The MaxInterval %d is negative", c.MaxInterval))
+	}
+	if c.PageSize < 0 || c.PageSize > MaxPageSize {
+		check(fmt.Errorf("The PageSize %d is out of range 0..%d", c.PageSize, MaxPageSize))
+	}
+	if c.MaxRows < 0 {
+		check(fmt.Errorf("The MaxRows %d is negative", c.MaxRows))
+	}
+	if c.RetryMaxAttempts < 0 {
+		check(fmt.Errorf("The RetryMaxAttempts %d is negative", c.RetryMaxAttempts))
+	}
+	return newConfigError(problems)
+}
+
+//validateS3URI accepts s3://bucket with an optional key prefix
+func validateS3URI(uri string) error {
+	rest := strings.TrimPrefix(uri, "s3://")
+	if rest == uri {
+		return fmt.Errorf("The OutputLocation %q is not an s3:// URI", uri)
+	}
+	bucket := strings.SplitN(rest, "/", 2)[0]
+	if !s3Bucket.MatchString(bucket) {
+		return fmt.Errorf("The OutputLocation %q has an invalid bucket name %q", uri, bucket)
+	}
+	return nil
+}
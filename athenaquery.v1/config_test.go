@@ -0,0 +1,72 @@
+package athena
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeConfigKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "output_location", want: "output_location"},
+		{key: "maxTimeout", want: "max_timeout"},
+		{key: "keepRunningOnTimeout", want: "keep_running_on_timeout"},
+		{key: "accessID", want: "access_id"},
+		{key: "roleMFASerial", want: "role_mfa_serial"},
+		{key: "s3ForcePathStyle", want: "s3_force_path_style"},
+		{key: "redactSQL", want: "redact_sql"},
+		{key: "workGroup", want: "workgroup"},
+		{key: "poll-frequency", want: "poll_frequency"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := NormalizeConfigKey(tt.key); got != tt.want {
+				t.Errorf("NormalizeConfigKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr []string
+	}{
+		{name: "t-valid", config: &Config{Region: "us-east-1", OutputLocation: "s3://my.bucket-1/athena/", MaxTimeout: 60, PollFrequency: "500ms"}},
+		{name: "t-workgroup-output", config: &Config{Role: "arn:xx:xx", WorkGroup: "team-a"}},
+		{name: "t-no-scheme", config: &Config{Region: "us-east-1", OutputLocation: "my-bucket/athena"}, wantErr: []string{"not an s3:// URI"}},
+		{name: "t-short-bucket", config: &Config{Region: "us-east-1", OutputLocation: "s3://ab"}, wantErr: []string{"invalid bucket name"}},
+		{name: "t-credentials", config: &Config{OutputLocation: "s3://bucket", WebIdentityTokenFile: "token"},
+			wantErr: []string{"is insufficient", "Role is missing"}},
+		{name: "t-durations", config: &Config{Region: "us-east-1", OutputLocation: "s3://bucket", RetryBackoff: "x", RoleDuration: "y", HTTPTimeout: "-1"},
+			wantErr: []string{"retry backoff", "role duration", "http timeout"}},
+		{name: "t-limits", config: &Config{Region: "us-east-1", OutputLocation: "s3://bucket", MaxInterval: -1, MaxRows: -1, RetryMaxAttempts: -1},
+			wantErr: []string{"MaxInterval", "MaxRows", "RetryMaxAttempts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != (len(tt.wantErr) > 0) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil {
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) || len(configErr.Problems) != len(tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, want %d problems", err, len(tt.wantErr))
+				return
+			}
+			for i, want := range tt.wantErr {
+				if !strings.Contains(configErr.Problems[i].Error(), want) {
+					t.Errorf("Config.Validate() problem %d = %v, want it to contain %q", i, configErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package athena
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//DecimalMode picks the Go type Decoder gives to decimal values
+type DecimalMode int
+
+const (
+	//DecimalAsString keeps the exact text, e.g. "12.30"
+	DecimalAsString DecimalMode = iota
+	//DecimalAsFloat64 converts to float64, which may lose precision
+	DecimalAsFloat64
+	//DecimalAsRat converts to an exact *big.Rat
+	DecimalAsRat
+)
+
+//TimeMode picks the Go type Decoder gives to date and timestamp values
+type TimeMode int
+
+const (
+	//TimeAsTime parses into time.Time
+	TimeAsTime TimeMode = iota
+	//TimeAsString keeps the text Athena returned
+	TimeAsString
+)
+
+//athenaResultTimestampLayout parses the timestamps of a result, whatever their fractional precision
+const athenaResultTimestampLayout = "2006-01-02 15:04:05.999999999"
+
+//Decoder turns the *string values of an athena.Row into Go values using ColumnInfo.Type.
+//NULL always decodes to nil, so it stays distinct from an empty varchar. The zero Decoder
+//keeps decimals as strings and parses dates and timestamps as UTC time.Time.
+//
+//	tinyint, smallint, integer, bigint  int64
+//	real, float, double                 float64
+//	decimal                             see Decimal
+//	boolean                             bool
+//	date, timestamp                     see Time, timestamp with time zone keeps its zone
+//	varchar, char, time and unknown     string
+//	varbinary                           []byte
+//	json                                json.RawMessage
+//	array                               []interface{}
+//	map, row                            map[string]interface{}, []interface{} for unnamed row fields
+//
+//Athena renders array, map and row values as text without quoting, so their elements are
+//decoded as strings (or nil for null) on a best-effort basis; cast them to JSON in the query
+//when the elements may contain ", ", "=" or brackets.
+//
+//A timestamp with time zone ends with an offset ("+05:30") or a zone name ("Europe/Berlin").
+//Zone names are looked up in the system zoneinfo; binaries running without it, e.g. on Lambda,
+//should import time/tzdata.
+type Decoder struct {
+	Decimal DecimalMode
+	Time    TimeMode
+	//Location applies to timestamps without a time zone and to dates, nil means UTC
+	Location *time.Location
+}
+
+//DecodeRows decodes every row with the column types of columns
+func (d *Decoder) DecodeRows(columns []*athena.ColumnInfo, rows []*athena.Row) ([][]interface{}, error) {
+	values := make([][]interface{}, 0, len(rows))
+	for i, row := range rows {
+		decoded, err := d.DecodeRow(columns, row)
+		if err != nil {
+			return nil, fmt.Errorf("The row %d is not decodable: %v", i, err)
+		}
+		values = append(values, decoded)
+	}
+	return values, nil
+}
+
+//DecodeRow decodes one row, which must have a value for each of columns
+func (d *Decoder) DecodeRow(columns []*athena.ColumnInfo, row *athena.Row) ([]interface{}, error) {
+	if row == nil {
+		return nil, fmt.Errorf("The row is nil")
+	}
+	if len(row.Data) != len(columns) {
+		return nil, fmt.Errorf("The row has %d values for %d columns", len(row.Data), len(columns))
+	}
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		value, err := d.Decode(column, row.Data[i])
+		if err != nil {
+			return nil, fmt.Errorf("The column %s: %v", aws.StringValue(column.Name), err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+//Decode decodes one value of column, a nil datum or value is NULL
+func (d *Decoder) Decode(column *athena.ColumnInfo, datum *athena.Datum) (interface{}, error) {
+	if datum == nil || datum.VarCharValue == nil {
+		return nil, nil
+	}
+	columnType := ""
+	if column != nil {
+		columnType = aws.StringValue(column.Type)
+	}
+	return d.DecodeValue(columnType, *datum.VarCharValue)
+}
+
+//DecodeValue decodes the non-NULL text value of an Athena type, see Decoder for the Go types
+func (d *Decoder) DecodeValue(columnType, value string) (interface{}, error) {
+	switch baseType(columnType) {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the %s %q is invalid", columnType, value)
+		}
+		return n, nil
+	case "real", "float", "double":
+		return parseDouble(columnType, value)
+	case "decimal":
+		return d.decodeDecimal(value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("the boolean %q is invalid", value)
+		}
+		return b, nil
+	case "date":
+		if d.Time == TimeAsString {
+			return value, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", value, d.location())
+		if err != nil {
+			return nil, fmt.Errorf("the date %q is invalid", value)
+		}
+		return t, nil
+	case "timestamp":
+		if d.Time == TimeAsString {
+			return value, nil
+		}
+		return d.decodeTimestamp(columnType, value)
+	case "varbinary":
+		b, err := hex.DecodeString(strings.Replace(value, " ", "", -1))
+		if err != nil {
+			return nil, fmt.Errorf("the varbinary %q is invalid", value)
+		}
+		return b, nil
+	case "json":
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("the json %q is invalid", value)
+		}
+		return json.RawMessage(value), nil
+	case "array", "map", "row":
+		return parseComplex(value)
+	}
+	return value, nil
+}
+
+func (d *Decoder) location() *time.Location {
+	if d.Location == nil {
+		return time.UTC
+	}
+	return d.Location
+}
+
+func (d *Decoder) decodeDecimal(value string) (interface{}, error) {
+	switch d.Decimal {
+	case DecimalAsFloat64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the decimal %q is invalid", value)
+		}
+		return f, nil
+	case DecimalAsRat:
+		r, ok := new(big.Rat).SetString(value)
+		if !ok {
+			return nil, fmt.Errorf("the decimal %q is invalid", value)
+		}
+		return r, nil
+	}
+	return value, nil
+}
+
+//decodeTimestamp parses "2006-01-02 15:04:05.000", with a trailing zone name for
+//timestamp with time zone
+func (d *Decoder) decodeTimestamp(columnType, value string) (interface{}, error) {
+	loc, text := d.location(), value
+	if strings.Contains(columnType, "with time zone") {
+		i := strings.LastIndex(value, " ")
+		if i < 0 {
+			return nil, fmt.Errorf("the %s %q has no time zone", columnType, value)
+		}
+		zone, err := parseZone(value[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("the %s %q has an unknown time zone: %v", columnType, value, err)
+		}
+		loc, text = zone, value[:i]
+	}
+	t, err := time.ParseInLocation(athenaResultTimestampLayout, text, loc)
+	if err != nil {
+		return nil, fmt.Errorf("the %s %q is invalid", columnType, value)
+	}
+	return t, nil
+}
+
+//parseZone reads the zone of a timestamp with time zone, a fixed offset like "+05:30" or a zone name
+func parseZone(zone string) (*time.Location, error) {
+	if strings.HasPrefix(zone, "+") || strings.HasPrefix(zone, "-") {
+		t, err := time.Parse("-07:00", zone)
+		if err != nil {
+			return nil, err
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(zone, offset), nil
+	}
+	return time.LoadLocation(zone)
+}
+
+//baseType strips the parameters of a type, e.g. "decimal(10,2)" becomes "decimal" and
+//"timestamp(3) with time zone" becomes "timestamp"
+func baseType(columnType string) string {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.IndexAny(t, "( <"); i >= 0 {
+		t = t[:i]
+	}
+	return t
+}
+
+func parseDouble(columnType, value string) (float64, error) {
+	switch value {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("the %s %q is invalid", columnType, value)
+	}
+	return f, nil
+}
+
+//parseComplex reads Athena's text of an array ("[1, 2]"), a map ("{a=1, b=2}") or a row
+//("{x=1, y=2}", or "{1, 2}" when its fields are unnamed)
+func parseComplex(value string) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return nil, fmt.Errorf("the complex value %q is invalid", value)
+	}
+	open, inner := value[0], value[1:len(value)-1]
+	if (open != '[' || value[len(value)-1] != ']') && (open != '{' || value[len(value)-1] != '}') {
+		return nil, fmt.Errorf("the complex value %q is invalid", value)
+	}
+	parts, err := splitTopLevel(inner, ", ")
+	if err != nil {
+		return nil, fmt.Errorf("the complex value %q is invalid: %v", value, err)
+	}
+
+	if open == '[' {
+		items := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			item, err := complexElement(part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	fields, unnamed := map[string]interface{}{}, []interface{}{}
+	for _, part := range parts {
+		eq := topLevelIndex(part, '=')
+		if eq < 0 {
+			item, err := complexElement(part)
+			if err != nil {
+				return nil, err
+			}
+			unnamed = append(unnamed, item)
+			continue
+		}
+		item, err := complexElement(part[eq+1:])
+		if err != nil {
+			return nil, err
+		}
+		fields[part[:eq]] = item
+	}
+	if len(unnamed) > 0 {
+		if len(fields) > 0 {
+			return nil, fmt.Errorf("the complex value %q mixes named and unnamed fields", value)
+		}
+		return unnamed, nil
+	}
+	return fields, nil
+}
+
+func complexElement(part string) (interface{}, error) {
+	switch {
+	case part == "null":
+		return nil, nil
+	case strings.HasPrefix(part, "[") || strings.HasPrefix(part, "{"):
+		return parseComplex(part)
+	}
+	return part, nil
+}
+
+//splitTopLevel splits s at sep outside of brackets
+func splitTopLevel(s, sep string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts, depth, start := []string{}, 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced brackets")
+			}
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[start:i])
+				start = i + len(sep)
+				i += len(sep) - 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets")
+	}
+	return append(parts, s[start:]), nil
+}
+
+//topLevelIndex returns the index of the first c outside of brackets, or -1
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
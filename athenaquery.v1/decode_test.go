@@ -0,0 +1,120 @@
+package athena
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestDecoder_DecodeValue(t *testing.T) {
+	newYork, zoneErr := time.LoadLocation("America/New_York")
+	if zoneErr != nil {
+		newYork = time.UTC
+	}
+	tests := []struct {
+		name       string
+		decoder    Decoder
+		columnType string
+		value      string
+		want       interface{}
+		wantErr    bool
+		zoneinfo   bool
+	}{
+		{name: "t-bigint", columnType: "bigint", value: "-42", want: int64(-42)},
+		{name: "t-integer", columnType: "integer", value: "7", want: int64(7)},
+		{name: "t-bad-integer", columnType: "integer", value: "7.5", wantErr: true},
+		{name: "t-double", columnType: "double", value: "1.5E3", want: 1500.0},
+		{name: "t-double-inf", columnType: "double", value: "-Infinity", want: math.Inf(-1)},
+		{name: "t-decimal-string", columnType: "decimal(10,2)", value: "12.30", want: "12.30"},
+		{name: "t-decimal-float", decoder: Decoder{Decimal: DecimalAsFloat64}, columnType: "decimal", value: "12.30", want: 12.3},
+		{name: "t-decimal-rat", decoder: Decoder{Decimal: DecimalAsRat}, columnType: "decimal", value: "0.1", want: big.NewRat(1, 10)},
+		{name: "t-boolean", columnType: "boolean", value: "true", want: true},
+		{name: "t-date", columnType: "date", value: "2024-02-29", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "t-date-string", decoder: Decoder{Time: TimeAsString}, columnType: "date", value: "2024-02-29", want: "2024-02-29"},
+		{name: "t-timestamp", columnType: "timestamp", value: "2024-02-29 13:14:15.123", want: time.Date(2024, 2, 29, 13, 14, 15, 123000000, time.UTC)},
+		{name: "t-timestamp-seconds", columnType: "timestamp", value: "2024-02-29 13:14:15", want: time.Date(2024, 2, 29, 13, 14, 15, 0, time.UTC)},
+		{name: "t-timestamp-location", decoder: Decoder{Location: newYork}, columnType: "timestamp", value: "2024-02-29 13:14:15.000",
+			want: time.Date(2024, 2, 29, 13, 14, 15, 0, newYork), zoneinfo: true},
+		{name: "t-timestamp-zone", columnType: "timestamp with time zone", value: "2024-02-29 13:14:15.000 America/New_York",
+			want: time.Date(2024, 2, 29, 13, 14, 15, 0, newYork), zoneinfo: true},
+		{name: "t-timestamp-offset", columnType: "timestamp(3) with time zone", value: "2024-02-29 13:14:15.000 +05:30",
+			want: time.Date(2024, 2, 29, 13, 14, 15, 0, time.FixedZone("+05:30", 5*3600+30*60))},
+		{name: "t-timestamp-negative-offset", columnType: "timestamp with time zone", value: "2024-02-29 13:14:15.000 -08:00",
+			want: time.Date(2024, 2, 29, 13, 14, 15, 0, time.FixedZone("-08:00", -8*3600))},
+		{name: "t-timestamp-bad-offset", columnType: "timestamp with time zone", value: "2024-02-29 13:14:15.000 +5", wantErr: true},
+		{name: "t-bad-timestamp", columnType: "timestamp", value: "yesterday", wantErr: true},
+		{name: "t-varchar-empty", columnType: "varchar", value: "", want: ""},
+		{name: "t-time", columnType: "time", value: "13:14:15.000", want: "13:14:15.000"},
+		{name: "t-unknown", columnType: "ipaddress", value: "10.0.0.1", want: "10.0.0.1"},
+		{name: "t-varbinary", columnType: "varbinary", value: "68 65 6c 6c 6f", want: []byte("hello")},
+		{name: "t-json", columnType: "json", value: `{"a":[1,2]}`, want: json.RawMessage(`{"a":[1,2]}`)},
+		{name: "t-bad-json", columnType: "json", value: `{"a":`, wantErr: true},
+		{name: "t-array", columnType: "array", value: "[1, null, 3]", want: []interface{}{"1", nil, "3"}},
+		{name: "t-array-empty", columnType: "array", value: "[]", want: []interface{}{}},
+		{name: "t-map", columnType: "map", value: "{a=1, b=[x, y]}", want: map[string]interface{}{"a": "1", "b": []interface{}{"x", "y"}}},
+		{name: "t-row", columnType: "row", value: "{id=1, tags={k=v}}", want: map[string]interface{}{"id": "1", "tags": map[string]interface{}{"k": "v"}}},
+		{name: "t-row-unnamed", columnType: "row", value: "{1, b}", want: []interface{}{"1", "b"}},
+		{name: "t-bad-array", columnType: "array", value: "[1, [2]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.zoneinfo && zoneErr != nil {
+				t.Skipf("time zone data is missing: %v", zoneErr)
+			}
+			got, err := tt.decoder.DecodeValue(tt.columnType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decoder.DecodeValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, _ := got.(time.Time); !got.Equal(want) || got.Location().String() != want.Location().String() {
+					t.Errorf("Decoder.DecodeValue() = %v, want %v", got, want)
+				}
+				return
+			}
+			if want, ok := tt.want.(*big.Rat); ok {
+				if got, _ := got.(*big.Rat); got == nil || got.Cmp(want) != 0 {
+					t.Errorf("Decoder.DecodeValue() = %v, want %v", got, want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decoder.DecodeValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeRows(t *testing.T) {
+	columns := []*athena.ColumnInfo{
+		{Name: aws.String("id"), Type: aws.String("bigint")},
+		{Name: aws.String("name"), Type: aws.String("varchar")},
+	}
+	rows := []*athena.Row{
+		{Data: []*athena.Datum{{VarCharValue: aws.String("1")}, {VarCharValue: aws.String("")}}},
+		{Data: []*athena.Datum{{VarCharValue: aws.String("2")}, {}}},
+		{Data: []*athena.Datum{nil, nil}},
+	}
+	d := &Decoder{}
+	got, err := d.DecodeRows(columns, rows)
+	if err != nil {
+		t.Fatalf("Decoder.DecodeRows() error = %v", err)
+	}
+	want := [][]interface{}{{int64(1), ""}, {int64(2), nil}, {nil, nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decoder.DecodeRows() = %v, want %v", got, want)
+	}
+
+	if _, err := d.DecodeRows(columns, []*athena.Row{{Data: []*athena.Datum{{VarCharValue: aws.String("id")}, {VarCharValue: aws.String("name")}}}}); err == nil {
+		t.Errorf("Decoder.DecodeRows() of a header row should fail on the bigint column")
+	}
+	if _, err := d.DecodeRow(columns, &athena.Row{Data: []*athena.Datum{{}}}); err == nil {
+		t.Errorf("Decoder.DecodeRow() with a missing value should fail")
+	}
+}
@@ -0,0 +1,320 @@
+package athena
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//DriverName is the name the database/sql driver is registered under
+const DriverName = "athena"
+
+//dsnScheme is the optional scheme of a DSN, see ParseDSN
+const dsnScheme = "athena://"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+//Driver is the database/sql driver, sql.Open("athena", dsn) builds an AthenaEngine from the DSN.
+//Use sql.OpenDB(NewConnector(engine, database)) to share an engine that already exists.
+type Driver struct{}
+
+//Open for driver.Driver
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+//OpenConnector for driver.DriverContext, the engine is built once and shared by every connection
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	config, database, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := GetInstance(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewConnector(engine, database), nil
+}
+
+//ParseDSN reads "athena://database?key=value&..." into the config and the default database.
+//The keys are the ones of BuildAthenaConfig, URL-escaped, and the scheme is optional, so
+//"region=us-east-1&output_location=s3%3A%2F%2Fbucket%2Fresults&database=sales" works too.
+func ParseDSN(dsn string) (*Config, string, error) {
+	database, query := "", dsn
+	if strings.HasPrefix(dsn, dsnScheme) {
+		rest := strings.TrimPrefix(dsn, dsnScheme)
+		if i := strings.Index(rest, "?"); i >= 0 {
+			database, query = rest[:i], rest[i+1:]
+		} else {
+			database, query = rest, ""
+		}
+		database = strings.Trim(database, "/")
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, "", fmt.Errorf("The Athena DSN is invalid: %v", err)
+	}
+	conf := map[string]string{}
+	for key, value := range values {
+		if len(value) > 1 {
+			return nil, "", fmt.Errorf("The Athena DSN sets %s more than once", key)
+		}
+		conf[key] = value[0]
+	}
+	if db, ok := conf["database"]; ok {
+		if database != "" && database != db {
+			return nil, "", fmt.Errorf("The Athena DSN names the databases %s and %s", database, db)
+		}
+		database = db
+		delete(conf, "database")
+	}
+	config, err := BuildAthenaConfig(conf)
+	if err != nil {
+		return nil, "", err
+	}
+	return config, database, nil
+}
+
+//Connector opens the database/sql connections of an AthenaEngine, queries run against database
+//unless they qualify their tables
+type Connector struct {
+	engine   *AthenaEngine
+	database string
+}
+
+//NewConnector wraps engine for sql.OpenDB
+func NewConnector(engine *AthenaEngine, database string) *Connector {
+	return &Connector{engine: engine, database: database}
+}
+
+//Connect for driver.Connector
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.engine == nil {
+		return nil, fmt.Errorf("The Athena Connector has no engine")
+	}
+	return &conn{engine: c.engine, database: c.database}, nil
+}
+
+//Driver for driver.Connector
+func (c *Connector) Driver() driver.Driver {
+	return &Driver{}
+}
+
+//conn is stateless, Athena has no sessions nor transactions
+type conn struct {
+	engine   *AthenaEngine
+	database string
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("The Athena driver does not support transactions")
+}
+
+//CheckNamedValue lets every type FormatParam knows through, e.g. Date, instead of the default conversion
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	_, err := FormatParam(nv.Value)
+	return err
+}
+
+//QueryContext runs query with args for its ? placeholders and streams the result through Rows
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryID, err := c.run(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	r := c.engine.Rows(ctx, queryID)
+	columns, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return &driverRows{rows: r, columns: columns}, nil
+}
+
+//ExecContext runs query, typically DDL, and waits for it without reading the result.
+//Athena reports no affected row count, so the result has none.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.run(ctx, query, args); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+//run starts query and waits until it succeeds, returning the id of the query that did
+func (c *conn) run(ctx context.Context, query string, args []driver.NamedValue) (string, error) {
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return "", fmt.Errorf("The Athena driver does not support the named arg %s, use ? placeholders", arg.Name)
+		}
+		params[i] = arg.Value
+	}
+	_, attempted, err := c.engine.runQuery(ctx, &RequestParam{SQL: query, DataBase: c.database, Params: params})
+	if err != nil {
+		return "", err
+	}
+	return attempted[len(attempted)-1], nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return countPlaceholders(s.query)
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	return s.conn.CheckNamedValue(nv)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+//driverRows adapts Rows to driver.Rows, values are decoded by the column type like Decoder does
+type driverRows struct {
+	rows    *Rows
+	columns []*athena.ColumnInfo
+	decoder Decoder
+}
+
+func (r *driverRows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = aws.StringValue(column.Name)
+	}
+	return names
+}
+
+func (r *driverRows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *driverRows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	row := r.rows.Row()
+	if len(row.Data) != len(r.columns) {
+		return fmt.Errorf("The row has %d values for %d columns", len(row.Data), len(r.columns))
+	}
+	for i, column := range r.columns {
+		value, err := r.driverValue(column, row.Data[i])
+		if err != nil {
+			return fmt.Errorf("The column %s: %v", aws.StringValue(column.Name), err)
+		}
+		dest[i] = value
+	}
+	return nil
+}
+
+//driverValue decodes datum into one of the types a driver.Value may hold, array, map and row
+//values keep Athena's text since database/sql has no type for them
+func (r *driverRows) driverValue(column *athena.ColumnInfo, datum *athena.Datum) (driver.Value, error) {
+	if datum == nil || datum.VarCharValue == nil {
+		return nil, nil
+	}
+	switch baseType(aws.StringValue(column.Type)) {
+	case "array", "map", "row":
+		return *datum.VarCharValue, nil
+	}
+	value, err := r.decoder.Decode(column, datum)
+	if raw, ok := value.(json.RawMessage); ok {
+		return []byte(raw), err
+	}
+	return value, err
+}
+
+//ColumnTypeDatabaseTypeName for driver.RowsColumnTypeDatabaseTypeName, e.g. "VARCHAR" or "DECIMAL"
+func (r *driverRows) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(baseType(aws.StringValue(r.columns[index].Type)))
+}
+
+//ColumnTypeNullable for driver.RowsColumnTypeNullable
+func (r *driverRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	switch aws.StringValue(r.columns[index].Nullable) {
+	case athena.ColumnNullableNotNull:
+		return false, true
+	case athena.ColumnNullableNullable:
+		return true, true
+	}
+	return false, false
+}
+
+//ColumnTypePrecisionScale for driver.RowsColumnTypePrecisionScale, only decimals have one
+func (r *driverRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	column := r.columns[index]
+	if baseType(aws.StringValue(column.Type)) != "decimal" {
+		return 0, 0, false
+	}
+	return aws.Int64Value(column.Precision), aws.Int64Value(column.Scale), true
+}
+
+//ColumnTypeScanType for driver.RowsColumnTypeScanType, the Go type Next stores for the column
+func (r *driverRows) ColumnTypeScanType(index int) reflect.Type {
+	switch baseType(aws.StringValue(r.columns[index].Type)) {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		return reflect.TypeOf(int64(0))
+	case "real", "float", "double":
+		return reflect.TypeOf(float64(0))
+	case "boolean":
+		return reflect.TypeOf(false)
+	case "date", "timestamp":
+		return reflect.TypeOf(time.Time{})
+	case "varbinary", "json":
+		return reflect.TypeOf([]byte(nil))
+	}
+	return reflect.TypeOf("")
+}
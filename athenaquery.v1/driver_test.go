@@ -0,0 +1,206 @@
+package athena
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+//MockAthenaClientDriver records the started query and serves rows after a header row
+type MockAthenaClientDriver struct {
+	athenaiface.AthenaAPI
+	input         *athena.StartQueryExecutionInput
+	statementType string
+	columns       []*athena.ColumnInfo
+	rows          [][]*string
+}
+
+func (m *MockAthenaClientDriver) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	m.input = input
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("12345-12345")}, nil
+}
+
+func (m *MockAthenaClientDriver) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		QueryExecutionId: input.QueryExecutionId,
+		StatementType:    aws.String(m.statementType),
+		Status:           &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)},
+	}}, nil
+}
+
+func (m *MockAthenaClientDriver) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	header := []*string{}
+	for _, column := range m.columns {
+		header = append(header, column.Name)
+	}
+	return &athena.GetQueryResultsOutput{ResultSet: &athena.ResultSet{
+		ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: m.columns},
+		Rows:              scanRows(append([][]*string{header}, m.rows...)...),
+	}}, nil
+}
+
+func TestParseDSN(t *testing.T) {
+	tests := []struct {
+		name         string
+		dsn          string
+		wantDatabase string
+		wantConfig   *Config
+		wantErr      bool
+	}{
+		{name: "t-url", dsn: "athena://sales?region=us-east-1&workgroup=primary&max_rows=10",
+			wantDatabase: "sales", wantConfig: &Config{Region: "us-east-1", WorkGroup: "primary", MaxRows: 10}},
+		{name: "t-query-only", dsn: "region=us-east-1&outputLocation=s3%3A%2F%2Fbucket%2Fresults&database=ops",
+			wantDatabase: "ops", wantConfig: &Config{Region: "us-east-1", OutputLocation: "s3://bucket/results"}},
+		{name: "t-no-database", dsn: "athena://?region=us-east-1&workgroup=primary",
+			wantConfig: &Config{Region: "us-east-1", WorkGroup: "primary"}},
+		{name: "t-database-conflict", dsn: "athena://sales?workgroup=primary&database=ops", wantErr: true},
+		{name: "t-repeated-key", dsn: "workgroup=primary&workgroup=other", wantErr: true},
+		{name: "t-bad-escape", dsn: "workgroup=%zz", wantErr: true},
+		{name: "t-invalid-config", dsn: "athena://sales?region=us-east-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, database, err := ParseDSN(tt.dsn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDSN() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if database != tt.wantDatabase {
+				t.Errorf("ParseDSN() database = %v, want %v", database, tt.wantDatabase)
+			}
+			if got.Region != tt.wantConfig.Region || got.WorkGroup != tt.wantConfig.WorkGroup ||
+				got.OutputLocation != tt.wantConfig.OutputLocation || got.MaxRows != tt.wantConfig.MaxRows {
+				t.Errorf("ParseDSN() = %+v, want %+v", got, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestDriver_Registered(t *testing.T) {
+	found := false
+	for _, name := range sql.Drivers() {
+		found = found || name == DriverName
+	}
+	if !found {
+		t.Errorf("sql.Drivers() = %v, want %v registered", sql.Drivers(), DriverName)
+	}
+	if _, err := sql.Open(DriverName, "athena://sales?region=us-east-1"); err == nil {
+		t.Errorf("sql.Open() with an invalid DSN should fail")
+	}
+}
+
+func TestDriver_Query(t *testing.T) {
+	mock := &MockAthenaClientDriver{
+		statementType: athena.StatementTypeDml,
+		columns: []*athena.ColumnInfo{
+			{Name: aws.String("id"), Type: aws.String("bigint"), Nullable: aws.String(athena.ColumnNullableNotNull)},
+			{Name: aws.String("name"), Type: aws.String("varchar"), Nullable: aws.String(athena.ColumnNullableNullable)},
+			{Name: aws.String("price"), Type: aws.String("decimal"), Precision: aws.Int64(10), Scale: aws.Int64(2)},
+			{Name: aws.String("day"), Type: aws.String("date")},
+			{Name: aws.String("tags"), Type: aws.String("array")},
+		},
+		rows: [][]*string{
+			{aws.String("1"), aws.String("ann"), aws.String("12.30"), aws.String("2024-02-29"), aws.String("[a, b]")},
+			{aws.String("2"), nil, aws.String("1.00"), aws.String("2024-03-01"), nil},
+		},
+	}
+	db := sql.OpenDB(NewConnector(&AthenaEngine{athena: mock}, "sales"))
+	defer db.Close()
+
+	day := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	rows, err := db.QueryContext(context.Background(), "SELECT * FROM orders WHERE id > ? AND day >= ?", 0, Date(day))
+	if err != nil {
+		t.Fatalf("sql.DB.QueryContext() error = %v", err)
+	}
+	defer rows.Close()
+
+	if got := aws.StringValue(mock.input.QueryExecutionContext.Database); got != "sales" {
+		t.Errorf("sql.DB.QueryContext() database = %v, want sales", got)
+	}
+	if got, want := aws.StringValueSlice(mock.input.ExecutionParameters), []string{"0", "DATE '2024-02-29'"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sql.DB.QueryContext() params = %v, want %v", got, want)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("sql.Rows.ColumnTypes() error = %v", err)
+	}
+	if got := types[0].DatabaseTypeName(); got != "BIGINT" {
+		t.Errorf("ColumnType.DatabaseTypeName() = %v, want BIGINT", got)
+	}
+	if nullable, ok := types[0].Nullable(); nullable || !ok {
+		t.Errorf("ColumnType.Nullable() = %v, %v, want false, true", nullable, ok)
+	}
+	if nullable, ok := types[1].Nullable(); !nullable || !ok {
+		t.Errorf("ColumnType.Nullable() = %v, %v, want true, true", nullable, ok)
+	}
+	if precision, scale, ok := types[2].DecimalSize(); precision != 10 || scale != 2 || !ok {
+		t.Errorf("ColumnType.DecimalSize() = %v, %v, %v", precision, scale, ok)
+	}
+	if got := types[3].ScanType(); got != reflect.TypeOf(time.Time{}) {
+		t.Errorf("ColumnType.ScanType() = %v, want time.Time", got)
+	}
+
+	type order struct {
+		id    int64
+		name  sql.NullString
+		price string
+		day   time.Time
+		tags  sql.NullString
+	}
+	got := []order{}
+	for rows.Next() {
+		var o order
+		if err := rows.Scan(&o.id, &o.name, &o.price, &o.day, &o.tags); err != nil {
+			t.Fatalf("sql.Rows.Scan() error = %v", err)
+		}
+		got = append(got, o)
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("sql.Rows.Err() = %v", err)
+	}
+	want := []order{
+		{id: 1, name: sql.NullString{String: "ann", Valid: true}, price: "12.30", day: day, tags: sql.NullString{String: "[a, b]", Valid: true}},
+		{id: 2, price: "1.00", day: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sql.Rows = %+v, want %+v", got, want)
+	}
+}
+
+func TestDriver_Exec(t *testing.T) {
+	mock := &MockAthenaClientDriver{statementType: athena.StatementTypeDdl}
+	db := sql.OpenDB(NewConnector(&AthenaEngine{athena: mock}, "sales"))
+	defer db.Close()
+
+	result, err := db.Exec("CREATE DATABASE IF NOT EXISTS sales")
+	if err != nil {
+		t.Fatalf("sql.DB.Exec() error = %v", err)
+	}
+	if got := aws.StringValue(mock.input.QueryString); got != "CREATE DATABASE IF NOT EXISTS sales" {
+		t.Errorf("sql.DB.Exec() query = %v", got)
+	}
+	if _, err := result.RowsAffected(); err == nil {
+		t.Errorf("sql.Result.RowsAffected() should fail, Athena reports none")
+	}
+
+	if _, err := db.Exec("DROP TABLE ?", sql.Named("table", "orders")); err == nil {
+		t.Errorf("sql.DB.Exec() with a named arg should fail")
+	}
+	if _, err := db.Exec("SELECT ?", make(chan int)); err == nil {
+		t.Errorf("sql.DB.Exec() with an unsupported arg should fail")
+	}
+	if _, err := db.Begin(); err == nil {
+		t.Errorf("sql.DB.Begin() should fail")
+	}
+}
@@ -0,0 +1,118 @@
+package athena
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//Athena error categories reported in AthenaError.ErrorCategory
+const (
+	ErrorCategorySystem int64 = 1
+	ErrorCategoryUser   int64 = 2
+	ErrorCategoryOther  int64 = 3
+)
+
+//QueryFailedError is returned when Athena reports the query as FAILED
+type QueryFailedError struct {
+	QueryID string
+	//Reason is the StateChangeReason of the query status
+	Reason string
+	//ErrorCategory is one of the ErrorCategory constants, zero when Athena sent no AthenaError
+	ErrorCategory int64
+	ErrorType     int64
+	ErrorMessage  string
+	Retryable     bool
+}
+
+func (e *QueryFailedError) Error() string {
+	msg := fmt.Sprintf("The Athena Query %s is failed", e.QueryID)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.ErrorCategory != 0 || e.ErrorType != 0 {
+		msg += fmt.Sprintf(" (category=%d, type=%d, retryable=%t)", e.ErrorCategory, e.ErrorType, e.Retryable)
+	}
+	return msg
+}
+
+//QueryCancelledError is returned when the query ends in the CANCELLED state
+type QueryCancelledError struct {
+	QueryID string
+	Reason  string
+}
+
+func (e *QueryCancelledError) Error() string {
+	msg := fmt.Sprintf("The Athena Query %s is cancelled", e.QueryID)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+//QueryTimeoutError is returned when the query is still pending after MaxTimeout
+type QueryTimeoutError struct {
+	QueryID string
+	//State is the last state seen before giving up
+	State   string
+	Elapsed time.Duration
+	Timeout time.Duration
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return fmt.Sprintf("The Athena Query %s is timeout in state %s after %v (timeout %v)", e.QueryID, e.State, e.Elapsed.Round(time.Millisecond), e.Timeout)
+}
+
+//newQueryFailedError reads the failure details from the query status
+func newQueryFailedError(queryID string, qe *athena.QueryExecution) *QueryFailedError {
+	e := &QueryFailedError{QueryID: queryID}
+	if qe == nil || qe.Status == nil {
+		return e
+	}
+	e.Reason = aws.StringValue(qe.Status.StateChangeReason)
+	if ae := qe.Status.AthenaError; ae != nil {
+		e.ErrorCategory = aws.Int64Value(ae.ErrorCategory)
+		e.ErrorType = aws.Int64Value(ae.ErrorType)
+		e.ErrorMessage = aws.StringValue(ae.ErrorMessage)
+		e.Retryable = aws.BoolValue(ae.Retryable)
+	}
+	return e
+}
+
+//newQueryCancelledError reads the cancellation reason from the query status
+func newQueryCancelledError(queryID string, qe *athena.QueryExecution) *QueryCancelledError {
+	e := &QueryCancelledError{QueryID: queryID}
+	if qe != nil && qe.Status != nil {
+		e.Reason = aws.StringValue(qe.Status.StateChangeReason)
+	}
+	return e
+}
+
+//ConfigError lists every problem found while building or validating a Config
+type ConfigError struct {
+	Problems []error
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return "The Athena config is invalid: " + strings.Join(msgs, "; ")
+}
+
+//Unwrap lets errors.Is and errors.As look at each problem
+func (e *ConfigError) Unwrap() []error {
+	return e.Problems
+}
+
+//newConfigError returns nil when there is no problem
+func newConfigError(problems []error) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: problems}
+}
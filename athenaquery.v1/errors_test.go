@@ -0,0 +1,125 @@
+package athena
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+//MockAthenaClientStatus always reports the given status
+type MockAthenaClientStatus struct {
+	athenaiface.AthenaAPI
+	status *athena.QueryExecutionStatus
+}
+
+func (m *MockAthenaClientStatus) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		QueryExecutionId: input.QueryExecutionId,
+		Status:           m.status,
+	}}, nil
+}
+
+func TestAthenaEngine_waitQueryToFinishErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        *athena.QueryExecutionStatus
+		wantFailed    *QueryFailedError
+		wantCancelled *QueryCancelledError
+		wantTimeout   bool
+	}{
+		{name: "t-failed",
+			status: &athena.QueryExecutionStatus{
+				State:             aws.String(athena.QueryExecutionStateFailed),
+				StateChangeReason: aws.String("Query exhausted resources at this scale factor"),
+				AthenaError: &athena.AthenaError{
+					ErrorCategory: aws.Int64(ErrorCategorySystem),
+					ErrorType:     aws.Int64(1001),
+					ErrorMessage:  aws.String("exhausted"),
+					Retryable:     aws.Bool(true),
+				},
+			},
+			wantFailed: &QueryFailedError{
+				QueryID:       "1234-1234",
+				Reason:        "Query exhausted resources at this scale factor",
+				ErrorCategory: ErrorCategorySystem,
+				ErrorType:     1001,
+				ErrorMessage:  "exhausted",
+				Retryable:     true,
+			},
+		},
+		{name: "t-failed-no-athena-error",
+			status: &athena.QueryExecutionStatus{
+				State:             aws.String(athena.QueryExecutionStateFailed),
+				StateChangeReason: aws.String("SYNTAX_ERROR"),
+			},
+			wantFailed: &QueryFailedError{QueryID: "1234-1234", Reason: "SYNTAX_ERROR"},
+		},
+		{name: "t-cancelled",
+			status: &athena.QueryExecutionStatus{
+				State:             aws.String(athena.QueryExecutionStateCancelled),
+				StateChangeReason: aws.String("Query cancelled by user"),
+			},
+			wantCancelled: &QueryCancelledError{QueryID: "1234-1234", Reason: "Query cancelled by user"},
+		},
+		{name: "t-timeout",
+			status:      &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateRunning)},
+			wantTimeout: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AthenaEngine{
+				athena:               &MockAthenaClientStatus{status: tt.status},
+				MaxTimeout:           1,
+				KeepRunningOnTimeout: true,
+				Poll:                 FixedPoll{Interval: 10 * time.Millisecond},
+			}
+			_, err := c.waitQueryToFinish(context.Background(), "1234-1234", time.Now().Add(-900*time.Millisecond))
+
+			var failed *QueryFailedError
+			if errors.As(err, &failed) != (tt.wantFailed != nil) || (failed != nil && !reflect.DeepEqual(failed, tt.wantFailed)) {
+				t.Errorf("AthenaEngine.waitQueryToFinish() error = %#v, want %#v", err, tt.wantFailed)
+			}
+			var cancelled *QueryCancelledError
+			if errors.As(err, &cancelled) != (tt.wantCancelled != nil) || (cancelled != nil && !reflect.DeepEqual(cancelled, tt.wantCancelled)) {
+				t.Errorf("AthenaEngine.waitQueryToFinish() error = %#v, want %#v", err, tt.wantCancelled)
+			}
+			var timeout *QueryTimeoutError
+			if errors.As(err, &timeout) != tt.wantTimeout {
+				t.Errorf("AthenaEngine.waitQueryToFinish() error = %#v, want timeout %v", err, tt.wantTimeout)
+			}
+			if timeout != nil && (timeout.State != athena.QueryExecutionStateRunning || timeout.Timeout != time.Second) {
+				t.Errorf("AthenaEngine.waitQueryToFinish() timeout = %#v", timeout)
+			}
+		})
+	}
+}
+
+func TestQueryErrors_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "t-failed", err: &QueryFailedError{QueryID: "q1"}, want: "The Athena Query q1 is failed"},
+		{name: "t-failed-reason", err: &QueryFailedError{QueryID: "q1", Reason: "boom", ErrorCategory: 2, ErrorType: 1301},
+			want: "The Athena Query q1 is failed: boom (category=2, type=1301, retryable=false)"},
+		{name: "t-cancelled", err: &QueryCancelledError{QueryID: "q1", Reason: "by user"}, want: "The Athena Query q1 is cancelled: by user"},
+		{name: "t-timeout", err: &QueryTimeoutError{QueryID: "q1", State: "QUEUED", Elapsed: 1500 * time.Millisecond, Timeout: time.Second},
+			want: "The Athena Query q1 is timeout in state QUEUED after 1.5s (timeout 1s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
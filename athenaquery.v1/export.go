@@ -0,0 +1,225 @@
+package athena
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//CSVOptions for WriteCSV, the zero value writes a header line, separates with commas,
+//quotes only the fields that need it and writes NULL as an empty field
+type CSVOptions struct {
+	NoHeader bool
+	//Comma separates the fields, 0 means ','
+	Comma rune
+	//QuoteAll quotes every field, not only the ones holding the separator, a quote or a line break
+	QuoteAll bool
+	UseCRLF  bool
+	//Null is written for NULL values
+	Null string
+}
+
+//JSONOptions for WriteJSON and WriteJSONLines, Decoder picks the typed value of each column.
+//Dates and timestamps become RFC 3339 strings, varbinary base64 strings, json columns are
+//embedded as is, and NaN or infinite doubles are written as the strings Athena returns.
+type JSONOptions struct {
+	Decoder Decoder
+}
+
+//resultReader walks the rows of a ResponseData or of a Rows iterator
+type resultReader interface {
+	Columns() ([]*athena.ColumnInfo, error)
+	Next() bool
+	Row() *athena.Row
+	Err() error
+}
+
+//dataReader is the resultReader of a ResponseData
+type dataReader struct {
+	data *ResponseData
+	pos  int
+}
+
+func (d *dataReader) Columns() ([]*athena.ColumnInfo, error) {
+	return d.data.Columns, nil
+}
+
+func (d *dataReader) Next() bool {
+	if d.pos >= len(d.data.Rows) {
+		return false
+	}
+	d.pos++
+	return true
+}
+
+func (d *dataReader) Row() *athena.Row {
+	return d.data.Rows[d.pos-1]
+}
+
+func (d *dataReader) Err() error {
+	return nil
+}
+
+//WriteCSV writes the columns and rows as CSV
+func (r *ResponseData) WriteCSV(w io.Writer, opts CSVOptions) error {
+	return writeCSV(w, &dataReader{data: r}, opts)
+}
+
+//WriteJSON writes the rows as a JSON array of objects keyed by column name, in column order
+func (r *ResponseData) WriteJSON(w io.Writer, opts JSONOptions) error {
+	return writeJSON(w, &dataReader{data: r}, opts, false)
+}
+
+//WriteJSONLines writes one JSON object per line (JSON Lines, also known as NDJSON)
+func (r *ResponseData) WriteJSONLines(w io.Writer, opts JSONOptions) error {
+	return writeJSON(w, &dataReader{data: r}, opts, true)
+}
+
+//WriteCSV writes the remaining rows as CSV, fetching the pages as it goes
+func (r *Rows) WriteCSV(w io.Writer, opts CSVOptions) error {
+	return writeCSV(w, r, opts)
+}
+
+//WriteJSON writes the remaining rows as a JSON array, see ResponseData.WriteJSON
+func (r *Rows) WriteJSON(w io.Writer, opts JSONOptions) error {
+	return writeJSON(w, r, opts, false)
+}
+
+//WriteJSONLines writes the remaining rows as JSON Lines, see ResponseData.WriteJSONLines
+func (r *Rows) WriteJSONLines(w io.Writer, opts JSONOptions) error {
+	return writeJSON(w, r, opts, true)
+}
+
+func writeCSV(w io.Writer, reader resultReader, opts CSVOptions) error {
+	columns, err := reader.Columns()
+	if err != nil {
+		return err
+	}
+	comma := opts.Comma
+	if comma == 0 {
+		comma = ','
+	}
+	if comma == '"' || comma == '\r' || comma == '\n' {
+		return fmt.Errorf("The CSV separator %q is invalid", comma)
+	}
+	lineEnd := "\n"
+	if opts.UseCRLF {
+		lineEnd = "\r\n"
+	}
+
+	out := bufio.NewWriter(w)
+	writeLine := func(fields []string) {
+		for i, field := range fields {
+			if i > 0 {
+				out.WriteRune(comma)
+			}
+			if opts.QuoteAll || strings.ContainsAny(field, string(comma)+"\"\r\n") || strings.HasPrefix(field, " ") {
+				field = `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+			}
+			out.WriteString(field)
+		}
+		out.WriteString(lineEnd)
+	}
+
+	fields := make([]string, len(columns))
+	if !opts.NoHeader {
+		for i, column := range columns {
+			fields[i] = aws.StringValue(column.Name)
+		}
+		writeLine(fields)
+	}
+	for reader.Next() {
+		row := reader.Row()
+		if len(row.Data) != len(columns) {
+			return fmt.Errorf("The row has %d values for %d columns", len(row.Data), len(columns))
+		}
+		for i, datum := range row.Data {
+			if datum == nil || datum.VarCharValue == nil {
+				fields[i] = opts.Null
+			} else {
+				fields[i] = *datum.VarCharValue
+			}
+		}
+		writeLine(fields)
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func writeJSON(w io.Writer, reader resultReader, opts JSONOptions, lines bool) error {
+	columns, err := reader.Columns()
+	if err != nil {
+		return err
+	}
+	names := make([][]byte, len(columns))
+	for i, column := range columns {
+		if names[i], err = json.Marshal(aws.StringValue(column.Name)); err != nil {
+			return err
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	if !lines {
+		out.WriteString("[")
+	}
+	for n := 0; reader.Next(); n++ {
+		if n > 0 && !lines {
+			out.WriteString(",")
+		}
+		row := reader.Row()
+		if len(row.Data) != len(columns) {
+			return fmt.Errorf("The row %d has %d values for %d columns", n, len(row.Data), len(columns))
+		}
+		out.WriteString("{")
+		for i, column := range columns {
+			value, err := jsonValue(&opts.Decoder, column, row.Data[i])
+			if err != nil {
+				return fmt.Errorf("The row %d column %s: %v", n, aws.StringValue(column.Name), err)
+			}
+			if i > 0 {
+				out.WriteString(",")
+			}
+			out.Write(names[i])
+			out.WriteString(":")
+			out.Write(value)
+		}
+		out.WriteString("}")
+		if lines {
+			out.WriteString("\n")
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return err
+	}
+	if !lines {
+		out.WriteString("]\n")
+	}
+	return out.Flush()
+}
+
+//jsonValue encodes the typed value of datum, keeping the exact text of decimals and the
+//Athena spelling of the doubles JSON has no number for
+func jsonValue(decoder *Decoder, column *athena.ColumnInfo, datum *athena.Datum) ([]byte, error) {
+	value, err := decoder.Decode(column, datum)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case *big.Rat:
+		return []byte(*datum.VarCharValue), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			value = *datum.VarCharValue
+		}
+	}
+	return json.Marshal(value)
+}
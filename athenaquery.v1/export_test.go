@@ -0,0 +1,124 @@
+package athena
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func exportData() *ResponseData {
+	return &ResponseData{
+		Columns: []*athena.ColumnInfo{
+			{Name: aws.String("id"), Type: aws.String("bigint")},
+			{Name: aws.String("name"), Type: aws.String("varchar")},
+			{Name: aws.String("price"), Type: aws.String("decimal(10,2)")},
+			{Name: aws.String("ratio"), Type: aws.String("double")},
+			{Name: aws.String("day"), Type: aws.String("date")},
+			{Name: aws.String("attrs"), Type: aws.String("json")},
+		},
+		Rows: scanRows(
+			[]*string{aws.String("1"), aws.String(`ann "a", b`), aws.String("12.30"), aws.String("0.5"), aws.String("2024-02-29"), aws.String(`{"k":1}`)},
+			[]*string{aws.String("2"), nil, aws.String("1.00"), aws.String("NaN"), nil, nil},
+		),
+	}
+}
+
+func TestResponseData_WriteCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CSVOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "t-default", want: "id,name,price,ratio,day,attrs\n" +
+			"1,\"ann \"\"a\"\", b\",12.30,0.5,2024-02-29,\"{\"\"k\"\":1}\"\n" +
+			"2,,1.00,NaN,,\n"},
+		{name: "t-options", opts: CSVOptions{NoHeader: true, Comma: ';', QuoteAll: true, UseCRLF: true, Null: `\N`}, want: "" +
+			"\"1\";\"ann \"\"a\"\", b\";\"12.30\";\"0.5\";\"2024-02-29\";\"{\"\"k\"\":1}\"\r\n" +
+			"\"2\";\"\\N\";\"1.00\";\"NaN\";\"\\N\";\"\\N\"\r\n"},
+		{name: "t-bad-comma", opts: CSVOptions{Comma: '"'}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportData().WriteCSV(&buf, tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("ResponseData.WriteCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); !tt.wantErr && got != tt.want {
+				t.Errorf("ResponseData.WriteCSV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseData_WriteJSON(t *testing.T) {
+	first := `{"id":1,"name":"ann \"a\", b","price":"12.30","ratio":0.5,"day":"2024-02-29T00:00:00Z","attrs":{"k":1}}`
+	second := `{"id":2,"name":null,"price":"1.00","ratio":"NaN","day":null,"attrs":null}`
+	firstRat := `{"id":1,"name":"ann \"a\", b","price":12.30,"ratio":0.5,"day":"2024-02-29T00:00:00Z","attrs":{"k":1}}`
+	tests := []struct {
+		name  string
+		data  *ResponseData
+		opts  JSONOptions
+		lines bool
+		want  string
+	}{
+		{name: "t-array", data: exportData(), want: "[" + first + "," + second + "]\n"},
+		{name: "t-lines", data: exportData(), lines: true, want: first + "\n" + second + "\n"},
+		{name: "t-decimal-rat", data: &ResponseData{Columns: exportData().Columns, Rows: exportData().Rows[:1]},
+			opts: JSONOptions{Decoder: Decoder{Decimal: DecimalAsRat}}, want: "[" + firstRat + "]\n"},
+		{name: "t-empty-array", data: &ResponseData{Columns: exportData().Columns}, want: "[]\n"},
+		{name: "t-empty-lines", data: &ResponseData{Columns: exportData().Columns}, lines: true, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var err error
+			if tt.lines {
+				err = tt.data.WriteJSONLines(&buf, tt.opts)
+			} else {
+				err = tt.data.WriteJSON(&buf, tt.opts)
+			}
+			if err != nil {
+				t.Errorf("ResponseData.WriteJSON() error = %v", err)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("ResponseData.WriteJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	bad := &ResponseData{Columns: exportData().Columns[:1], Rows: scanRows([]*string{aws.String("x")})}
+	if err := bad.WriteJSON(&bytes.Buffer{}, JSONOptions{}); err == nil {
+		t.Errorf("ResponseData.WriteJSON() of an invalid bigint should fail")
+	}
+}
+
+func TestRows_Write(t *testing.T) {
+	mock := &MockAthenaClientPaged{pages: []string{"a", "b"}, header: true, statementType: athena.StatementTypeDml}
+	var buf bytes.Buffer
+	if err := (&AthenaEngine{athena: mock}).Rows(context.Background(), "1234-1234").WriteCSV(&buf, CSVOptions{}); err != nil {
+		t.Fatalf("Rows.WriteCSV() error = %v", err)
+	}
+	if got, want := buf.String(), "id\na\nb\n"; got != want {
+		t.Errorf("Rows.WriteCSV() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := (&AthenaEngine{athena: mock}).Rows(context.Background(), "1234-1234").WriteJSONLines(&buf, JSONOptions{}); err != nil {
+		t.Fatalf("Rows.WriteJSONLines() error = %v", err)
+	}
+	if got, want := buf.String(), "{\"id\":\"a\"}\n{\"id\":\"b\"}\n"; got != want {
+		t.Errorf("Rows.WriteJSONLines() = %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := (&AthenaEngine{athena: mock}).Rows(ctx, "1234-1234").WriteJSON(&bytes.Buffer{}, JSONOptions{}); err == nil {
+		t.Errorf("Rows.WriteJSON() with a cancelled context should fail")
+	}
+}
@@ -0,0 +1,51 @@
+package athena
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//idempotencyKey returns RequestParam.IdempotencyKey, or with DeriveIdempotencyKey a key derived
+//from the request's QueryID. Only with DeriveIdempotencyKeyFromContent does a request without
+//a QueryID get a key derived from everything the start sends to Athena.
+func (c *AthenaEngine) idempotencyKey(qi *RequestParam, input *athena.StartQueryExecutionInput) string {
+	if qi.IdempotencyKey != "" {
+		return qi.IdempotencyKey
+	}
+	if !c.DeriveIdempotencyKey && !c.DeriveIdempotencyKeyFromContent {
+		return ""
+	}
+	if qi.QueryID != "" {
+		return "query-id:" + qi.QueryID
+	}
+	if !c.DeriveIdempotencyKeyFromContent {
+		return ""
+	}
+	parts := []string{
+		aws.StringValue(input.QueryString),
+		aws.StringValue(input.QueryExecutionContext.Catalog),
+		aws.StringValue(input.QueryExecutionContext.Database),
+		aws.StringValue(input.WorkGroup),
+	}
+	if input.ResultConfiguration != nil {
+		parts = append(parts, aws.StringValue(input.ResultConfiguration.OutputLocation))
+	}
+	parts = append(parts, aws.StringValueSlice(input.ExecutionParameters)...)
+	return "query:" + strings.Join(parts, "\x00")
+}
+
+//idempotencyToken hashes key into a ClientRequestToken, which Athena wants 32 to 128 characters
+//long. Re-submissions (attempt > 1) get their own token, otherwise Athena would hand back the
+//failed query execution of the first attempt.
+func idempotencyToken(key string, attempt int) string {
+	if attempt > 1 {
+		key += "\x00attempt:" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
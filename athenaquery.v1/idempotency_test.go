@@ -0,0 +1,103 @@
+package athena
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestIdempotencyToken(t *testing.T) {
+	first := idempotencyToken("key", 1)
+	if len(first) < 32 || len(first) > 128 {
+		t.Errorf("idempotencyToken() length = %d, want 32..128", len(first))
+	}
+	if idempotencyToken("key", 1) != first || idempotencyToken("key", 0) != first {
+		t.Errorf("idempotencyToken() of the first attempt is not stable")
+	}
+	if idempotencyToken("key", 2) == first || idempotencyToken("key", 2) == idempotencyToken("key", 3) {
+		t.Errorf("idempotencyToken() of re-submissions should differ")
+	}
+	if idempotencyToken("other", 1) == first {
+		t.Errorf("idempotencyToken() of different keys should differ")
+	}
+}
+
+func TestAthenaEngine_ExecuteQueryIdempotency(t *testing.T) {
+	sql := "SELECT * FROM t WHERE id = ?"
+	tests := []struct {
+		name        string
+		derive      bool
+		fromContent bool
+		param       *RequestParam
+		wantToken   *string
+		wantNone    bool
+		sameAs      *RequestParam
+		differs     *RequestParam
+	}{
+		{name: "t-none", param: &RequestParam{SQL: sql, Params: []interface{}{1}}, wantNone: true},
+		{name: "t-explicit", param: &RequestParam{SQL: sql, Params: []interface{}{1}, IdempotencyKey: "event-1"},
+			wantToken: aws.String(idempotencyToken("event-1", 1))},
+		{name: "t-explicit-without-derive", derive: true, param: &RequestParam{SQL: sql, Params: []interface{}{1}, IdempotencyKey: "event-1", QueryID: "req-1"},
+			wantToken: aws.String(idempotencyToken("event-1", 1))},
+		{name: "t-derived-query-id", derive: true, param: &RequestParam{SQL: sql, Params: []interface{}{1}, QueryID: "req-1"},
+			wantToken: aws.String(idempotencyToken("query-id:req-1", 1)),
+			sameAs:    &RequestParam{SQL: "SELECT 2", QueryID: "req-1"}, differs: &RequestParam{SQL: sql, Params: []interface{}{1}, QueryID: "req-2"}},
+		{name: "t-derive-not-from-sql", derive: true, param: &RequestParam{SQL: sql, DataBase: "db", Params: []interface{}{1}}, wantNone: true},
+		{name: "t-derived-sql", fromContent: true, param: &RequestParam{SQL: sql, DataBase: "db", Params: []interface{}{1}},
+			sameAs: &RequestParam{SQL: sql, DataBase: "db", Params: []interface{}{int64(1)}}, differs: &RequestParam{SQL: sql, DataBase: "db", Params: []interface{}{2}}},
+		{name: "t-derived-content-query-id", fromContent: true, param: &RequestParam{SQL: sql, Params: []interface{}{1}, QueryID: "req-1"},
+			wantToken: aws.String(idempotencyToken("query-id:req-1", 1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := func(param *RequestParam) *string {
+				mock := &MockAthenaClientStart{}
+				c := &AthenaEngine{athena: mock, DeriveIdempotencyKey: tt.derive, DeriveIdempotencyKeyFromContent: tt.fromContent, OutputLocation: "s3://bucket"}
+				if _, err := c.ExecuteQueryContext(context.Background(), param); err != nil {
+					t.Fatalf("AthenaEngine.ExecuteQueryContext() error = %v", err)
+				}
+				return mock.input.ClientRequestToken
+			}
+			got := token(tt.param)
+			if tt.wantToken != nil && !reflect.DeepEqual(got, tt.wantToken) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() token = %v, want %v", aws.StringValue(got), aws.StringValue(tt.wantToken))
+			}
+			if tt.wantNone != (got == nil) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() token = %v, wantNone %v", aws.StringValue(got), tt.wantNone)
+			}
+			if tt.sameAs != nil && aws.StringValue(token(tt.sameAs)) != aws.StringValue(got) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() token of %v should equal the one of %v", tt.sameAs, tt.param)
+			}
+			if tt.differs != nil && aws.StringValue(token(tt.differs)) == aws.StringValue(got) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() token of %v should differ from the one of %v", tt.differs, tt.param)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_QueryResultIdempotentRetry(t *testing.T) {
+	mock := &MockAthenaClientRetry{failures: 1, status: retryableStatus}
+	c := &AthenaEngine{
+		athena:        mock,
+		pollFrequency: time.Millisecond,
+		Retry:         &RetryPolicy{MaxAttempts: 3, Backoff: FixedPoll{Interval: time.Millisecond}},
+	}
+	param := &RequestParam{SQL: "SELECT 1", IdempotencyKey: "event-1"}
+
+	// the second run is the Lambda retry of the same event
+	for run := 1; run <= 2; run++ {
+		got, err := c.QueryResult(param)
+		if err != nil {
+			t.Fatalf("run %d: AthenaEngine.QueryResult() error = %v", run, err)
+		}
+		if want := []string{"q-1", "q-2"}; !reflect.DeepEqual(got.AttemptedQueryIDs, want) {
+			t.Errorf("run %d: AthenaEngine.QueryResult() attempted = %v, want %v", run, got.AttemptedQueryIDs, want)
+		}
+	}
+	if mock.starts != 2 || len(mock.tokens) != 2 {
+		t.Errorf("AthenaEngine.QueryResult() started %d queries with %d tokens, want 2 and 2", mock.starts, len(mock.tokens))
+	}
+}
@@ -0,0 +1,272 @@
+package athena
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//configKeys are the canonical keys read by BuildAthenaConfig, EnvSource looks each of them up
+var configKeys = []string{
+	"region", "output_location", "workgroup", "catalog", "poll_frequency", "poll_strategy",
+	"access_id", "secret_key", "session_token", "role",
+	"max_interval", "max_timeout", "page_size", "max_rows",
+	"keep_running_on_timeout", "retry_max_attempts", "retry_backoff",
+	"derive_idempotency_key", "derive_idempotency_key_from_content",
+	"role_external_id", "role_session_name", "role_duration", "role_source_identity", "role_mfa_serial",
+	"web_identity_token_file",
+	"athena_endpoint", "s3_endpoint", "sts_endpoint", "s3_force_path_style",
+	"http_timeout", "insecure_skip_verify", "ca_bundle_file",
+	"redact_sql",
+}
+
+//fileSectionKey is the optional top-level section of a config file holding the Athena settings,
+//matching the athena.<data source> layout used with viper
+const fileSectionKey = "athena"
+
+//ConfigSource yields raw config values, with keys in either style BuildAthenaConfig accepts.
+//Sources are combined by LoadConfig, where a later source overrides an earlier one key by key.
+type ConfigSource interface {
+	//Values returns the values for dataSource, an empty dataSource asks for the shared values only
+	Values(dataSource string) (map[string]string, error)
+}
+
+//dataSourceLister is implemented by the sources that know their data source names
+type dataSourceLister interface {
+	DataSources() ([]string, error)
+}
+
+//MapSource gives the same values to every data source
+type MapSource map[string]string
+
+//Values for MapSource
+func (m MapSource) Values(dataSource string) (map[string]string, error) {
+	return m, nil
+}
+
+//DataSourceMap holds the values of each data source, keyed by its name
+type DataSourceMap map[string]map[string]string
+
+//Values for DataSourceMap, an unknown dataSource has no values
+func (m DataSourceMap) Values(dataSource string) (map[string]string, error) {
+	return m[dataSource], nil
+}
+
+//DataSources for DataSourceMap
+func (m DataSourceMap) DataSources() ([]string, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+//EnvSource reads the variables named by its prefix and the upper-cased key, e.g. ATHENA_MAX_TIMEOUT
+//for EnvSource("ATHENA"). A variable that also names the data source, e.g. ATHENA_SALES_MAX_TIMEOUT,
+//overrides the shared one for that data source. Empty variables are ignored.
+type EnvSource string
+
+//Values for EnvSource
+func (e EnvSource) Values(dataSource string) (map[string]string, error) {
+	values := map[string]string{}
+	prefixes := []string{envName(string(e))}
+	if dataSource != "" {
+		prefixes = append(prefixes, envName(string(e), dataSource))
+	}
+	for _, prefix := range prefixes {
+		for _, key := range configKeys {
+			if value, ok := os.LookupEnv(envName(prefix, key)); ok && value != "" {
+				values[key] = value
+			}
+		}
+	}
+	return values, nil
+}
+
+//envName joins the non-empty parts with _ and upper-cases them, - becomes _
+func envName(parts ...string) string {
+	names := []string{}
+	for _, p := range parts {
+		if p != "" {
+			names = append(names, strings.ToUpper(strings.Replace(p, "-", "_", -1)))
+		}
+	}
+	return strings.Join(names, "_")
+}
+
+//FileSource reads a JSON (.json) or YAML (.yaml, .yml) file. Scalar values at the top level, or
+//under an "athena" section, are shared by every data source; a nested map is the section of the
+//data source it is named after and overrides the shared values.
+type FileSource string
+
+//Values for FileSource
+func (f FileSource) Values(dataSource string) (map[string]string, error) {
+	shared, sections, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for key, value := range shared {
+		values[key] = value
+	}
+	if dataSource != "" {
+		for key, value := range sections[dataSource] {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+//DataSources for FileSource
+func (f FileSource) DataSources() ([]string, error) {
+	_, sections, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	return DataSourceMap(sections).DataSources()
+}
+
+func (f FileSource) read() (map[string]string, map[string]map[string]string, error) {
+	path := string(f)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("The config file is not readable: %v", err)
+	}
+	doc := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&doc)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	default:
+		return nil, nil, fmt.Errorf("The config file %s is neither JSON nor YAML", path)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("The config file %s is invalid: %v", path, err)
+	}
+	if section, ok := doc[fileSectionKey].(map[string]interface{}); ok {
+		doc = section
+	}
+
+	shared, sections := map[string]string{}, map[string]map[string]string{}
+	for key, value := range doc {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			s, err := scalarString(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("The config file %s key %s: %v", path, key, err)
+			}
+			shared[key] = s
+			continue
+		}
+		section := map[string]string{}
+		for k, v := range nested {
+			s, err := scalarString(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("The config file %s key %s.%s: %v", path, key, k, err)
+			}
+			section[k] = s
+		}
+		sections[key] = section
+	}
+	return shared, sections, nil
+}
+
+//scalarString renders a decoded JSON or YAML scalar the way a config map holds it
+func scalarString(v interface{}) (string, error) {
+	switch s := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return s, nil
+	case json.Number:
+		return s.String(), nil
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), nil
+	case bool, int, int64, uint64:
+		return fmt.Sprint(s), nil
+	}
+	return "", fmt.Errorf("the value %v is not a scalar", v)
+}
+
+//mergeSources combines the values of sources for dataSource, later sources win key by key
+func mergeSources(dataSource string, sources []ConfigSource) (map[string]string, []error) {
+	merged, problems := map[string]string{}, []error{}
+	for _, source := range sources {
+		values, err := source.Values(dataSource)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		r := newConfigReader(values)
+		problems = append(problems, r.problems...)
+		for key, value := range r.values {
+			merged[key] = value
+		}
+	}
+	return merged, problems
+}
+
+//LoadConfig builds the config of dataSource from sources, a later source overrides an earlier one
+//key by key. A typical Lambda setup is LoadConfig("", FileSource("athena.yaml"), EnvSource("ATHENA")).
+func LoadConfig(dataSource string, sources ...ConfigSource) (*Config, error) {
+	merged, problems := mergeSources(dataSource, sources)
+	if len(problems) > 0 {
+		return nil, newConfigError(problems)
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("The config sources have no value for the data source %q", dataSource)
+	}
+	return BuildAthenaConfig(merged)
+}
+
+//LoadConfigs runs LoadConfig for every data source named by a DataSourceMap or FileSource in
+//sources, the problems of all data sources are reported together
+func LoadConfigs(sources ...ConfigSource) (map[string]*Config, error) {
+	names, problems := map[string]bool{}, []error{}
+	for _, source := range sources {
+		lister, ok := source.(dataSourceLister)
+		if !ok {
+			continue
+		}
+		list, err := lister.DataSources()
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for _, name := range list {
+			names[name] = true
+		}
+	}
+	if len(problems) > 0 {
+		return nil, newConfigError(problems)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("The config sources name no data source")
+	}
+
+	configs := map[string]*Config{}
+	for name := range names {
+		config, err := LoadConfig(name, sources...)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("The data source %s: %v", name, err))
+			continue
+		}
+		configs[name] = config
+	}
+	if len(problems) > 0 {
+		sort.Slice(problems, func(i, j int) bool { return problems[i].Error() < problems[j].Error() })
+		return nil, newConfigError(problems)
+	}
+	return configs, nil
+}
@@ -0,0 +1,158 @@
+package athena
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv("ATHENA_REGION", "us-east-1")
+	t.Setenv("ATHENA_OUTPUT_LOCATION", "s3://shared")
+	t.Setenv("ATHENA_MAX_TIMEOUT", "")
+	t.Setenv("ATHENA_SALES_EU_OUTPUT_LOCATION", "s3://sales")
+	t.Setenv("OTHER_ROLE", "arn:xx:xx")
+
+	tests := []struct {
+		name       string
+		dataSource string
+		want       map[string]string
+	}{
+		{name: "t-shared", want: map[string]string{"region": "us-east-1", "output_location": "s3://shared"}},
+		{name: "t-data-source", dataSource: "sales-eu", want: map[string]string{"region": "us-east-1", "output_location": "s3://sales"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnvSource("ATHENA").Values(tt.dataSource)
+			if err != nil {
+				t.Errorf("EnvSource.Values() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvSource.Values() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	yamlFile := writeConfigFile(t, "athena.yaml", `
+athena:
+  region: us-east-1
+  maxTimeout: 60
+  keep_running_on_timeout: true
+  sales:
+    output_location: s3://sales/results
+    maxTimeout: 120
+  ops:
+    output_location: s3://ops
+`)
+	jsonFile := writeConfigFile(t, "athena.json", `{"region": "eu-west-1", "output_location": "s3://bucket", "page_size": 500}`)
+	largeFile := writeConfigFile(t, "large.json", `{"max_rows": 1000000, "max_timeout": 12345678}`)
+	largeYAMLFile := writeConfigFile(t, "large.yaml", "max_rows: 1e6\nmax_timeout: 12345678\n")
+	listFile := writeConfigFile(t, "list.yml", "region: [a, b]\n")
+
+	tests := []struct {
+		name       string
+		path       string
+		dataSource string
+		want       map[string]string
+		wantErr    bool
+	}{
+		{name: "t-yaml-shared", path: yamlFile, want: map[string]string{"region": "us-east-1", "maxTimeout": "60", "keep_running_on_timeout": "true"}},
+		{name: "t-yaml-section", path: yamlFile, dataSource: "sales",
+			want: map[string]string{"region": "us-east-1", "maxTimeout": "120", "keep_running_on_timeout": "true", "output_location": "s3://sales/results"}},
+		{name: "t-json", path: jsonFile, dataSource: "any", want: map[string]string{"region": "eu-west-1", "output_location": "s3://bucket", "page_size": "500"}},
+		{name: "t-json-large", path: largeFile, want: map[string]string{"max_rows": "1000000", "max_timeout": "12345678"}},
+		{name: "t-yaml-large", path: largeYAMLFile, want: map[string]string{"max_rows": "1000000", "max_timeout": "12345678"}},
+		{name: "t-list", path: listFile, wantErr: true},
+		{name: "t-missing", path: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: true},
+		{name: "t-extension", path: writeConfigFile(t, "athena.toml", "region = 'x'"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FileSource(tt.path).Values(tt.dataSource)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FileSource.Values() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileSource.Values() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	names, err := FileSource(yamlFile).DataSources()
+	if err != nil || !reflect.DeepEqual(names, []string{"ops", "sales"}) {
+		t.Errorf("FileSource.DataSources() = %v, %v", names, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	file := writeConfigFile(t, "athena.yml", "region: us-east-1\noutputLocation: s3://file\nmax_timeout: 60\n")
+	t.Setenv("ATHENA_OUTPUT_LOCATION", "s3://env")
+
+	tests := []struct {
+		name    string
+		sources []ConfigSource
+		want    *Config
+		wantErr string
+	}{
+		{name: "t-env-over-file", sources: []ConfigSource{FileSource(file), EnvSource("ATHENA")},
+			want: &Config{Region: "us-east-1", OutputLocation: "s3://env", MaxTimeout: 60}},
+		{name: "t-file-over-env", sources: []ConfigSource{EnvSource("ATHENA"), FileSource(file)},
+			want: &Config{Region: "us-east-1", OutputLocation: "s3://file", MaxTimeout: 60}},
+		{name: "t-map-override", sources: []ConfigSource{FileSource(file), MapSource{"maxTimeout": "30"}},
+			want: &Config{Region: "us-east-1", OutputLocation: "s3://file", MaxTimeout: 30}},
+		{name: "t-no-values", sources: []ConfigSource{MapSource{}}, wantErr: "no value"},
+		{name: "t-invalid", sources: []ConfigSource{FileSource(file), MapSource{"max_timeout": "soon"}}, wantErr: "not an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfig("", tt.sources...)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	sources := DataSourceMap{
+		"sales": {"region": "us-east-1", "output_location": "s3://sales"},
+		"ops":   {"role": "arn:xx:xx", "workgroup": "ops"},
+	}
+	got, err := LoadConfigs(MapSource{"max_timeout": "60"}, sources, MapSource{"pageSize": "100"})
+	if err != nil {
+		t.Fatalf("LoadConfigs() error = %v", err)
+	}
+	want := map[string]*Config{
+		"sales": {Region: "us-east-1", OutputLocation: "s3://sales", MaxTimeout: 60, PageSize: 100},
+		"ops":   {Role: "arn:xx:xx", WorkGroup: "ops", MaxTimeout: 60, PageSize: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadConfigs() = %v, want %v", got, want)
+	}
+
+	_, err = LoadConfigs(DataSourceMap{"a": {"region": "x"}, "b": {"output_location": "bucket"}})
+	if err == nil || !strings.Contains(err.Error(), "The data source a") || !strings.Contains(err.Error(), "The data source b") {
+		t.Errorf("LoadConfigs() error = %v, want problems of both data sources", err)
+	}
+	if _, err := LoadConfigs(MapSource{"region": "x"}); err == nil {
+		t.Errorf("LoadConfigs() without data sources should fail")
+	}
+}
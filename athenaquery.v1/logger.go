@@ -0,0 +1,154 @@
+package athena
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+//Keys of the structured fields the engine logs with
+const (
+	LogKeyQueryID    = "query_id"
+	LogKeyState      = "query_state"
+	LogKeyDuration   = "duration"
+	LogKeyError      = "error"
+	LogKeySQL        = "sql"
+	LogKeyCatalog    = "catalog"
+	LogKeyDataBase   = "database"
+	LogKeyWorkGroup  = "workgroup"
+	LogKeyParamCount = "param_count"
+	LogKeyRegion     = "region"
+	LogKeyRole       = "role"
+	LogKeyAccessID   = "access_id"
+)
+
+//redactedValue replaces the value of a secret field
+const redactedValue = "[REDACTED]"
+
+//Logger receives the engine's log records as a message plus key/value pairs, the same shape as
+//log/slog so a *slog.Logger can be used directly
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+//NopLogger drops every record, it is the default of AthenaEngine
+type NopLogger struct{}
+
+//Debug for NopLogger
+func (NopLogger) Debug(msg string, args ...interface{}) {}
+
+//Info for NopLogger
+func (NopLogger) Info(msg string, args ...interface{}) {}
+
+//Warn for NopLogger
+func (NopLogger) Warn(msg string, args ...interface{}) {}
+
+//Error for NopLogger
+func (NopLogger) Error(msg string, args ...interface{}) {}
+
+//NewSlogLogger adapts l to Logger, a nil l uses slog.Default()
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
+
+//secretKeys are field keys whose values are never logged
+var secretKeys = map[string]bool{
+	"secret_key":        true,
+	"secret_access_key": true,
+	"session_token":     true,
+	"token":             true,
+	"password":          true,
+}
+
+//maskedKeys are field keys whose values are logged with all but the last 4 characters masked
+var maskedKeys = map[string]bool{
+	LogKeyAccessID: true,
+	"access_key":   true,
+}
+
+//sqlLiteral matches single-quoted SQL string literals, with '' as the escaped quote
+var sqlLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+//redactingLogger hides credentials, and string literals of the logged SQL when redactSQL is set,
+//before handing the record to next
+type redactingLogger struct {
+	next      Logger
+	redactSQL bool
+}
+
+//newRedactingLogger wraps next, a nil next drops every record
+func newRedactingLogger(next Logger, redactSQL bool) Logger {
+	if next == nil {
+		return NopLogger{}
+	}
+	if r, ok := next.(*redactingLogger); ok {
+		next = r.next
+	}
+	return &redactingLogger{next: next, redactSQL: redactSQL}
+}
+
+//Debug for redactingLogger
+func (l *redactingLogger) Debug(msg string, args ...interface{}) {
+	l.next.Debug(msg, l.redact(args)...)
+}
+
+//Info for redactingLogger
+func (l *redactingLogger) Info(msg string, args ...interface{}) {
+	l.next.Info(msg, l.redact(args)...)
+}
+
+//Warn for redactingLogger
+func (l *redactingLogger) Warn(msg string, args ...interface{}) {
+	l.next.Warn(msg, l.redact(args)...)
+}
+
+//Error for redactingLogger
+func (l *redactingLogger) Error(msg string, args ...interface{}) {
+	l.next.Error(msg, l.redact(args)...)
+}
+
+func (l *redactingLogger) redact(args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	copy(out, args)
+	for i := 0; i+1 < len(out); i += 2 {
+		key, ok := out[i].(string)
+		if !ok {
+			continue
+		}
+		switch lower := strings.ToLower(key); {
+		case secretKeys[lower]:
+			out[i+1] = redactedValue
+		case maskedKeys[lower]:
+			out[i+1] = maskValue(out[i+1])
+		case lower == LogKeySQL && l.redactSQL:
+			if sql, ok := out[i+1].(string); ok {
+				out[i+1] = RedactSQLLiterals(sql)
+			}
+		}
+	}
+	return out
+}
+
+//maskValue keeps the last 4 characters of a string value like the AWS console does, the first
+//ones tell keys nothing apart since every access key ID starts with AKIA or ASIA
+func maskValue(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return redactedValue
+	}
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
+
+//RedactSQLLiterals replaces every single-quoted string literal of sql with '***'
+func RedactSQLLiterals(sql string) string {
+	return sqlLiteral.ReplaceAllString(sql, "'***'")
+}
@@ -0,0 +1,122 @@
+package athena
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//logRecord is one call received by MockLogger
+type logRecord struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+//MockLogger records every call
+type MockLogger struct {
+	records []logRecord
+}
+
+func (m *MockLogger) Debug(msg string, args ...interface{}) { m.add("debug", msg, args) }
+func (m *MockLogger) Info(msg string, args ...interface{})  { m.add("info", msg, args) }
+func (m *MockLogger) Warn(msg string, args ...interface{})  { m.add("warn", msg, args) }
+func (m *MockLogger) Error(msg string, args ...interface{}) { m.add("error", msg, args) }
+
+func (m *MockLogger) add(level, msg string, args []interface{}) {
+	m.records = append(m.records, logRecord{level: level, msg: msg, args: args})
+}
+
+//field returns the value logged under key in the first record with msg
+func (m *MockLogger) field(msg, key string) (interface{}, bool) {
+	for _, r := range m.records {
+		if r.msg != msg {
+			continue
+		}
+		for i := 0; i+1 < len(r.args); i += 2 {
+			if r.args[i] == key {
+				return r.args[i+1], true
+			}
+		}
+	}
+	return nil, false
+}
+
+func TestRedactingLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		redactSQL bool
+		args      []interface{}
+		want      []interface{}
+	}{
+		{name: "t-secrets", args: []interface{}{"secret_key", "s3cr3t", "session_token", "tok", "Password", "pw"},
+			want: []interface{}{"secret_key", redactedValue, "session_token", redactedValue, "Password", redactedValue}},
+		{name: "t-access-id", args: []interface{}{LogKeyAccessID, "AKIAABCDEFGH", "access_key", "AK"},
+			want: []interface{}{LogKeyAccessID, "********EFGH", "access_key", "**"}},
+		{name: "t-sql-kept", args: []interface{}{LogKeySQL, "SELECT * FROM t WHERE name = 'bob'"},
+			want: []interface{}{LogKeySQL, "SELECT * FROM t WHERE name = 'bob'"}},
+		{name: "t-sql-redacted", redactSQL: true, args: []interface{}{LogKeySQL, "SELECT * FROM t WHERE name = 'o''brien' AND id = 1"},
+			want: []interface{}{LogKeySQL, "SELECT * FROM t WHERE name = '***' AND id = 1"}},
+		{name: "t-other", args: []interface{}{LogKeyQueryID, "q-1", LogKeyDuration, time.Second, "dangling"},
+			want: []interface{}{LogKeyQueryID, "q-1", LogKeyDuration, time.Second, "dangling"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockLogger{}
+			newRedactingLogger(mock, tt.redactSQL).Info("msg", tt.args...)
+			if len(mock.records) != 1 || !reflect.DeepEqual(mock.records[0].args, tt.want) {
+				t.Errorf("redactingLogger.Info() records = %v, want args %v", mock.records, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if _, err := NewSessionWithKeys("us-east-1", "AKIAABCDEFGH", "very-secret", WithLogger(logger)); err != nil {
+		t.Fatalf("NewSessionWithKeys() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "very-secret") || strings.Contains(out, "AKIAABCDEFGH") {
+		t.Errorf("NewSessionWithKeys() logged credentials: %s", out)
+	}
+	if !strings.Contains(out, "access_id=********EFGH") || !strings.Contains(out, "region=us-east-1") {
+		t.Errorf("NewSessionWithKeys() log = %s", out)
+	}
+	if NewSlogLogger(nil) == nil {
+		t.Errorf("NewSlogLogger(nil) should fall back to slog.Default()")
+	}
+}
+
+func TestAthenaEngine_Logger(t *testing.T) {
+	mock := &MockLogger{}
+	c := &AthenaEngine{athena: &MockAthenaClient{}, Logger: mock, RedactSQL: true, pollFrequency: time.Millisecond}
+	if _, err := c.QueryResult(&RequestParam{SQL: "SELECT * FROM t WHERE name = 'bob'", DataBase: "db"}); err != nil {
+		t.Fatalf("AthenaEngine.QueryResult() error = %v", err)
+	}
+	if sql, _ := mock.field("Executing Athena Query", LogKeySQL); sql != "SELECT * FROM t WHERE name = '***'" {
+		t.Errorf("AthenaEngine logged sql = %v", sql)
+	}
+	if id, _ := mock.field("Athena Query Finished", LogKeyQueryID); id != "12345-12345" {
+		t.Errorf("AthenaEngine logged query id = %v", id)
+	}
+	if state, _ := mock.field("Athena Query Finished", LogKeyState); state != "SUCCEEDED" {
+		t.Errorf("AthenaEngine logged state = %v", state)
+	}
+	if _, ok := mock.field("Athena Query Finished", LogKeyDuration); !ok {
+		t.Errorf("AthenaEngine logged no duration: %v", mock.records)
+	}
+	if state, _ := mock.field("Athena Query Status", LogKeyState); state != "SUCCEEDED" {
+		t.Errorf("AthenaEngine logged status state = %v", state)
+	}
+
+	// the default engine has no logger and must not panic
+	c = &AthenaEngine{athena: &MockAthenaClient{}, pollFrequency: time.Millisecond}
+	if _, err := c.QueryResult(&RequestParam{SQL: "SELECT 1"}); err != nil {
+		t.Errorf("AthenaEngine.QueryResult() without Logger error = %v", err)
+	}
+}
@@ -1,8 +1,9 @@
 package athena
 
 import (
-	"strconv"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/athena"
 )
@@ -11,13 +12,51 @@ import (
 type Config struct {
 	Region         string
 	OutputLocation string
+	WorkGroup      string
+	Catalog        string
 	PollFrequency  string
+	PollStrategy   string
 	AccessID       string
 	SecretKey      string
 	SessionToken   string
 	Role           string
 	MaxInterval    int
 	MaxTimeout     int
+	PageSize       int
+	MaxRows        int
+
+	KeepRunningOnTimeout bool
+	RetryMaxAttempts     int
+	RetryBackoff         string
+	//DeriveIdempotencyKey and DeriveIdempotencyKeyFromContent, see AthenaEngine
+	DeriveIdempotencyKey            bool
+	DeriveIdempotencyKeyFromContent bool
+
+	RoleExternalID     string
+	RoleSessionName    string
+	RoleDuration       string
+	RoleSourceIdentity string
+	RoleMFASerial      string
+	//RoleMFATokenProvider returns the MFA code when RoleMFASerial is set, attach it before GetInstance
+	RoleMFATokenProvider func() (string, error)
+	//WebIdentityTokenFile switches Role to web identity federation, e.g. IRSA in EKS
+	WebIdentityTokenFile string
+
+	//AthenaEndpoint, S3Endpoint and STSEndpoint override the service endpoints, e.g. for LocalStack
+	AthenaEndpoint   string
+	S3Endpoint       string
+	STSEndpoint      string
+	S3ForcePathStyle bool
+	//HTTPTimeout is a Go duration or a plain number of seconds, empty means no timeout
+	HTTPTimeout        string
+	InsecureSkipVerify bool
+	CABundleFile       string
+	//HTTPClient replaces the HTTP client built from the settings above, it can't come from a config map
+	HTTPClient *http.Client
+
+	//Logger receives the engine's logs, see AthenaEngine.Logger. It can't come from a config map.
+	Logger    Logger
+	RedactSQL bool
 }
 
 //AthenaRequestParam for request
@@ -28,34 +67,86 @@ type RequestParam struct {
 	DataBase   string
 	NetworkID  int64
 	QueryOpt   string
+	WorkGroup  string
+	Catalog    string
+	PageSize   int
+	MaxRows    int
+	//Params fill the ? placeholders of SQL in order, see FormatParam for the supported types
+	Params []interface{}
+	//IdempotencyKey makes a repeated start of the same request return the first query execution
+	IdempotencyKey string
 }
 
 //AthenaResponseData for response
 type ResponseData struct {
-	Columns     []*athena.ColumnInfo
+	Columns []*athena.ColumnInfo
+	//Header is the row of column names Athena puts first in a SELECT result, nil for DDL and
+	//utility statements whose results have none. Rows never include it.
+	Header      *athena.Row
 	Rows        []*athena.Row
 	QueryID     string
 	QueryStatus string
+	Truncated   bool
+	QueuedTime  time.Duration
+	RunningTime time.Duration
+
+	AttemptedQueryIDs []string
 }
 
-//BuildAthenaConfig for athena engine
-func BuildAthenaConfig(conf map[string]string) *Config {
+//BuildAthenaConfig for athena engine. Keys are accepted both in snake_case ("max_timeout") and
+//camelCase ("maxTimeout"), every unparsable value and Validate problem is reported in one *ConfigError.
+func BuildAthenaConfig(conf map[string]string) (*Config, error) {
 	if len(conf) == 0 {
-		fmt.Errorf("BuildDBConfig error: input is nil.")
-		return nil
+		return nil, fmt.Errorf("The Athena config map is empty")
 	}
 
-	maxIv, _ := strconv.Atoi(conf["maxInterval"])
-	maxTo, _ := strconv.Atoi(conf["maxTimeout"])
-	return &Config{
-		OutputLocation: conf["output_location"],
-		PollFrequency:  conf["poll_frequency"],
-		MaxInterval:    maxIv,
-		MaxTimeout:     maxTo,
-		AccessID:       conf["access_id"],
-		SecretKey:      conf["secret_key"],
-		SessionToken:   conf["session_token"],
-		Role:           conf["role"],
-		Region:         conf["region"],
+	r := newConfigReader(conf)
+	config := &Config{
+		OutputLocation: r.str("output_location"),
+		WorkGroup:      r.str("workgroup"),
+		Catalog:        r.str("catalog"),
+		PollFrequency:  r.str("poll_frequency"),
+		PollStrategy:   r.str("poll_strategy"),
+		MaxInterval:    r.int("max_interval"),
+		MaxTimeout:     r.int("max_timeout"),
+		PageSize:       r.int("page_size"),
+		MaxRows:        r.int("max_rows"),
+		AccessID:       r.str("access_id"),
+		SecretKey:      r.str("secret_key"),
+		SessionToken:   r.str("session_token"),
+		Role:           r.str("role"),
+		Region:         r.str("region"),
+
+		KeepRunningOnTimeout: r.bool("keep_running_on_timeout"),
+		RetryMaxAttempts:     r.int("retry_max_attempts"),
+		RetryBackoff:         r.str("retry_backoff"),
+		DeriveIdempotencyKey: r.bool("derive_idempotency_key"),
+
+		DeriveIdempotencyKeyFromContent: r.bool("derive_idempotency_key_from_content"),
+
+		RoleExternalID:     r.str("role_external_id"),
+		RoleSessionName:    r.str("role_session_name"),
+		RoleDuration:       r.str("role_duration"),
+		RoleSourceIdentity: r.str("role_source_identity"),
+		RoleMFASerial:      r.str("role_mfa_serial"),
+
+		WebIdentityTokenFile: r.str("web_identity_token_file"),
+
+		AthenaEndpoint:     r.str("athena_endpoint"),
+		S3Endpoint:         r.str("s3_endpoint"),
+		STSEndpoint:        r.str("sts_endpoint"),
+		S3ForcePathStyle:   r.bool("s3_force_path_style"),
+		HTTPTimeout:        r.str("http_timeout"),
+		InsecureSkipVerify: r.bool("insecure_skip_verify"),
+		CABundleFile:       r.str("ca_bundle_file"),
+
+		RedactSQL: r.bool("redact_sql"),
+	}
+	if err := config.Validate(); err != nil {
+		r.problems = append(r.problems, err.(*ConfigError).Problems...)
+	}
+	if err := newConfigError(r.problems); err != nil {
+		return nil, err
 	}
+	return config, nil
 }
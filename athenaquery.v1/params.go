@@ -0,0 +1,126 @@
+package athena
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Date marks a time.Time to be sent as an Athena DATE literal instead of a TIMESTAMP
+type Date time.Time
+
+//athenaTimestampLayout is the literal format Athena accepts for TIMESTAMP values
+const athenaTimestampLayout = "2006-01-02 15:04:05.000"
+
+//FormatParam converts a Go value into the Athena literal sent in ExecutionParameters.
+//Strings are quoted with single quotes doubled, time.Time becomes a UTC TIMESTAMP,
+//Date a DATE, []byte a varbinary X'..' literal and nil (or a nil pointer) NULL.
+func FormatParam(v interface{}) (string, error) {
+	switch p := v.(type) {
+	case nil:
+		return "NULL", nil
+	case driver.Valuer:
+		value, err := p.Value()
+		if err != nil {
+			return "", err
+		}
+		if _, again := value.(driver.Valuer); again {
+			return "", fmt.Errorf("The param %T returns another driver.Valuer", v)
+		}
+		return FormatParam(value)
+	case string:
+		return quoteString(p), nil
+	case []byte:
+		return "X'" + strings.ToUpper(hex.EncodeToString(p)) + "'", nil
+	case bool:
+		return strconv.FormatBool(p), nil
+	case time.Time:
+		return "TIMESTAMP '" + p.UTC().Format(athenaTimestampLayout) + "'", nil
+	case Date:
+		return "DATE '" + time.Time(p).Format("2006-01-02") + "'", nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return FormatParam(rv.Elem().Interface())
+	case reflect.String:
+		return quoteString(rv.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("The param %v has no Athena literal", f)
+		}
+		// the exponent makes Athena read it as DOUBLE rather than DECIMAL
+		return strconv.FormatFloat(f, 'E', -1, 64), nil
+	}
+	return "", fmt.Errorf("The param type %T is not supported", v)
+}
+
+//formatParams checks params against the ? placeholders in sql and formats each of them
+func formatParams(sql string, params []interface{}) ([]string, error) {
+	if n := countPlaceholders(sql); n != len(params) {
+		return nil, fmt.Errorf("The query has %d placeholders but %d params", n, len(params))
+	}
+	literals := make([]string, 0, len(params))
+	for i, p := range params {
+		literal, err := FormatParam(p)
+		if err != nil {
+			return nil, fmt.Errorf("The param %d is invalid: %v", i+1, err)
+		}
+		literals = append(literals, literal)
+	}
+	return literals, nil
+}
+
+//countPlaceholders counts the ? in sql that are not inside a string literal,
+//a quoted identifier or a comment
+func countPlaceholders(sql string) int {
+	count := 0
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case sql[i] == '?':
+			count++
+		case sql[i] == '\'' || sql[i] == '"':
+			quote := sql[i]
+			for i++; i < len(sql); i++ {
+				if sql[i] == quote {
+					if i+1 < len(sql) && sql[i+1] == quote {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return count
+			}
+			i += end + 3
+		}
+	}
+	return count
+}
+
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,129 @@
+package athena
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+//MockAthenaClientStart records the StartQueryExecution input
+type MockAthenaClientStart struct {
+	athenaiface.AthenaAPI
+	input *athena.StartQueryExecutionInput
+}
+
+func (m *MockAthenaClientStart) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	m.input = input
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("12345-12345")}, nil
+}
+
+type networkID int64
+
+func TestFormatParam(t *testing.T) {
+	ts := time.Date(2020, 8, 25, 13, 4, 5, 123000000, time.FixedZone("UTC+8", 8*3600))
+	name := "o'brien"
+	var nilName *string
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "t-nil", value: nil, want: "NULL"},
+		{name: "t-string", value: "viewership", want: "'viewership'"},
+		{name: "t-string-quote", value: "it's'; DROP TABLE x; --", want: "'it''s''; DROP TABLE x; --'"},
+		{name: "t-int", value: 42, want: "42"},
+		{name: "t-int64-negative", value: int64(-7), want: "-7"},
+		{name: "t-uint", value: uint32(7), want: "7"},
+		{name: "t-named-int", value: networkID(9), want: "9"},
+		{name: "t-float", value: 1.5, want: "1.5E+00"},
+		{name: "t-float-nan", value: math.NaN(), wantErr: true},
+		{name: "t-bool", value: true, want: "true"},
+		{name: "t-time", value: ts, want: "TIMESTAMP '2020-08-25 05:04:05.123'"},
+		{name: "t-date", value: Date(ts), want: "DATE '2020-08-25'"},
+		{name: "t-bytes", value: []byte("hi"), want: "X'6869'"},
+		{name: "t-pointer", value: &name, want: "'o''brien'"},
+		{name: "t-nil-pointer", value: nilName, want: "NULL"},
+		{name: "t-valuer", value: sql.NullInt64{Int64: 3, Valid: true}, want: "3"},
+		{name: "t-valuer-null", value: sql.NullString{}, want: "NULL"},
+		{name: "t-unsupported", value: struct{}{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatParam(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FormatParam() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FormatParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want int
+	}{
+		{name: "t-none", sql: "SELECT 1", want: 0},
+		{name: "t-two", sql: "SELECT * FROM t WHERE a = ? AND b = ?", want: 2},
+		{name: "t-string-literal", sql: "SELECT '?', 'it''s ?' FROM t WHERE a = ?", want: 1},
+		{name: "t-identifier", sql: `SELECT "col?" FROM t WHERE a = ?`, want: 1},
+		{name: "t-line-comment", sql: "SELECT a -- is it ?\nFROM t WHERE a = ?", want: 1},
+		{name: "t-block-comment", sql: "SELECT /* ? */ a FROM t WHERE a = ?", want: 1},
+		{name: "t-unclosed-comment", sql: "SELECT ? /* ?", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countPlaceholders(tt.sql); got != tt.want {
+				t.Errorf("countPlaceholders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_ExecuteQueryParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   *RequestParam
+		want    []*string
+		wantErr bool
+	}{
+		{name: "t-no-params", param: &RequestParam{SQL: "SELECT 1"}},
+		{name: "t-params", param: &RequestParam{SQL: "SELECT * FROM t WHERE id = ? AND day = ?", Params: []interface{}{"a'b", 20200825}},
+			want: aws.StringSlice([]string{"'a''b'", "20200825"})},
+		{name: "t-count-mismatch", param: &RequestParam{SQL: "SELECT * FROM t WHERE id = ?", Params: []interface{}{1, 2}}, wantErr: true},
+		{name: "t-bad-param", param: &RequestParam{SQL: "SELECT ?", Params: []interface{}{struct{}{}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientStart{}
+			c := &AthenaEngine{athena: mock}
+			_, err := c.ExecuteQueryContext(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if mock.input != nil {
+					t.Errorf("AthenaEngine.ExecuteQueryContext() should not start the query")
+				}
+				return
+			}
+			if !reflect.DeepEqual(mock.input.ExecutionParameters, tt.want) {
+				t.Errorf("AthenaEngine.ExecuteQueryContext() params = %v, want %v", aws.StringValueSlice(mock.input.ExecutionParameters), aws.StringValueSlice(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package athena
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PollStrategyFixed       = "fixed"
+	PollStrategyExponential = "exponential"
+)
+
+//DefaultPollFrequency is used when the config leaves PollFrequency empty
+const DefaultPollFrequency = 3 * time.Second
+
+//DefaultMaxInterval caps the exponential waits when the config leaves MaxInterval at 0
+const DefaultMaxInterval = time.Minute
+
+//PollStrategy decides how long waitQueryToFinish sleeps between two status checks
+type PollStrategy interface {
+	//Delay returns the wait after the given poll attempt, attempts start at 0
+	Delay(attempt int) time.Duration
+}
+
+//FixedPoll waits the same Interval between every status check
+type FixedPoll struct {
+	Interval time.Duration
+}
+
+//Delay for FixedPoll
+func (p FixedPoll) Delay(attempt int) time.Duration {
+	return p.Interval
+}
+
+//ExponentialPoll starts with Initial and multiplies the wait by Multiplier after every attempt,
+//never going above Max when Max is set. Jitter in [0, 1] shortens each wait by a random
+//fraction up to that value, so concurrent Lambdas don't poll in lockstep.
+type ExponentialPoll struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+//Delay for ExponentialPoll
+func (p ExponentialPoll) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	wait := float64(p.Initial) * math.Pow(multiplier, float64(attempt))
+	if p.Max > 0 && wait > float64(p.Max) {
+		wait = float64(p.Max)
+	}
+	if wait >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		wait -= wait * jitter * rand.Float64()
+	}
+	return time.Duration(wait)
+}
+
+//NewPollStrategy builds the strategy named in Config.PollStrategy.
+//frequency is the (initial) wait, maxInterval caps the exponential growth, DefaultMaxInterval when 0.
+func NewPollStrategy(name string, frequency, maxInterval time.Duration) (PollStrategy, error) {
+	if frequency <= 0 {
+		frequency = DefaultPollFrequency
+	}
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxInterval
+	}
+	switch strings.ToLower(name) {
+	case "", PollStrategyFixed:
+		return FixedPoll{Interval: frequency}, nil
+	case PollStrategyExponential:
+		return ExponentialPoll{Initial: frequency, Max: maxInterval, Multiplier: 2, Jitter: 0.2}, nil
+	}
+	return nil, fmt.Errorf("The poll strategy %q is unknown", name)
+}
+
+//ParsePollFrequency accepts a Go duration ("500ms", "2s") or a plain number of seconds
+func ParsePollFrequency(value string) (time.Duration, error) {
+	return parseDuration("poll frequency", value)
+}
+
+//parseDuration reads a Go duration or a plain number of seconds, name is used in the error
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("The %s %q is negative", name, value)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("The %s %q is invalid: %v", name, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("The %s %q is negative", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,107 @@
+package athena
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFixedPoll_Delay(t *testing.T) {
+	p := FixedPoll{Interval: 2 * time.Second}
+	for attempt := 0; attempt < 3; attempt++ {
+		if got := p.Delay(attempt); got != 2*time.Second {
+			t.Errorf("FixedPoll.Delay(%d) = %v, want %v", attempt, got, 2*time.Second)
+		}
+	}
+}
+
+func TestExponentialPoll_Delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		poll    ExponentialPoll
+		attempt int
+		want    time.Duration
+	}{
+		{name: "t-first", poll: ExponentialPoll{Initial: time.Second, Multiplier: 2}, attempt: 0, want: time.Second},
+		{name: "t-grows", poll: ExponentialPoll{Initial: time.Second, Multiplier: 2}, attempt: 3, want: 8 * time.Second},
+		{name: "t-default-multiplier", poll: ExponentialPoll{Initial: time.Second}, attempt: 2, want: 4 * time.Second},
+		{name: "t-capped", poll: ExponentialPoll{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}, attempt: 10, want: 5 * time.Second},
+		{name: "t-overflow", poll: ExponentialPoll{Initial: time.Second, Max: time.Minute, Multiplier: 2}, attempt: 5000, want: time.Minute},
+		{name: "t-overflow-uncapped", poll: ExponentialPoll{Initial: time.Second, Multiplier: 2}, attempt: 40, want: time.Duration(math.MaxInt64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.poll.Delay(tt.attempt); got != tt.want {
+				t.Errorf("ExponentialPoll.Delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialPoll_DelayJitter(t *testing.T) {
+	p := ExponentialPoll{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := p.Delay(2); got < 2*time.Second || got > 4*time.Second {
+			t.Errorf("ExponentialPoll.Delay() = %v, want within [2s, 4s]", got)
+		}
+	}
+}
+
+func TestNewPollStrategy(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    string
+		frequency   time.Duration
+		maxInterval time.Duration
+		want        PollStrategy
+		wantErr     bool
+	}{
+		{name: "t-default", want: FixedPoll{Interval: DefaultPollFrequency}},
+		{name: "t-fixed", strategy: "fixed", frequency: time.Second, want: FixedPoll{Interval: time.Second}},
+		{name: "t-exponential", strategy: "Exponential", frequency: time.Second, maxInterval: 30 * time.Second,
+			want: ExponentialPoll{Initial: time.Second, Max: 30 * time.Second, Multiplier: 2, Jitter: 0.2}},
+		{name: "t-exponential-default-max", strategy: "exponential", frequency: time.Second,
+			want: ExponentialPoll{Initial: time.Second, Max: DefaultMaxInterval, Multiplier: 2, Jitter: 0.2}},
+		{name: "t-unknown", strategy: "linear", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPollStrategy(tt.strategy, tt.frequency, tt.maxInterval)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPollStrategy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewPollStrategy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePollFrequency(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "t-empty", value: "", want: 0},
+		{name: "t-seconds", value: "5", want: 5 * time.Second},
+		{name: "t-duration", value: "250ms", want: 250 * time.Millisecond},
+		{name: "t-negative", value: "-1", wantErr: true},
+		{name: "t-negative-duration", value: "-1s", wantErr: true},
+		{name: "t-invalid", value: "often", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePollFrequency(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePollFrequency() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParsePollFrequency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
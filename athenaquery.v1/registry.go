@@ -0,0 +1,172 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//DefaultDataSource is the data source NewRegistry picks as default when it is configured
+const DefaultDataSource = "default"
+
+//Registry holds one AthenaEngine per data source, e.g. per account, region or workgroup, and
+//routes requests by RequestParam.DataSource. Engines are built from their config on first use.
+type Registry struct {
+	mu            sync.Mutex
+	configs       map[string]*Config
+	engines       map[string]*AthenaEngine
+	defaultSource string
+	//newEngine builds the engine of a config, it is GetInstance outside of tests
+	newEngine func(*Config) (*AthenaEngine, error)
+}
+
+//NewRegistry for the configs keyed by data source. Requests with an empty DataSource go to
+//defaultSource; when it is empty, DefaultDataSource or the only configured data source is used.
+func NewRegistry(configs map[string]*Config, defaultSource string) (*Registry, error) {
+	r := &Registry{
+		configs:   map[string]*Config{},
+		engines:   map[string]*AthenaEngine{},
+		newEngine: GetInstance,
+	}
+	for name, config := range configs {
+		if err := r.Register(name, config); err != nil {
+			return nil, err
+		}
+	}
+	if defaultSource == "" {
+		if _, ok := r.configs[DefaultDataSource]; ok {
+			defaultSource = DefaultDataSource
+		} else if len(r.configs) == 1 {
+			for name := range r.configs {
+				defaultSource = name
+			}
+		}
+	}
+	if defaultSource != "" {
+		if _, ok := r.configs[defaultSource]; !ok {
+			return nil, fmt.Errorf("The default data source %s is not configured", defaultSource)
+		}
+	}
+	r.defaultSource = defaultSource
+	return r, nil
+}
+
+//LoadRegistry builds a Registry from the data sources found in sources, see LoadConfigs
+func LoadRegistry(defaultSource string, sources ...ConfigSource) (*Registry, error) {
+	configs, err := LoadConfigs(sources...)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(configs, defaultSource)
+}
+
+//Register adds or replaces the config of a data source, its engine is rebuilt on next use
+func (r *Registry) Register(name string, config *Config) error {
+	if name == "" {
+		return fmt.Errorf("The data source name is missing")
+	}
+	if config == nil {
+		return fmt.Errorf("The config of the data source %s is nil", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[name] = config
+	delete(r.engines, name)
+	return nil
+}
+
+//RegisterEngine adds or replaces the data source with an engine that is already set up
+func (r *Registry) RegisterEngine(name string, engine *AthenaEngine) error {
+	if name == "" {
+		return fmt.Errorf("The data source name is missing")
+	}
+	if engine == nil {
+		return fmt.Errorf("The engine of the data source %s is nil", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.configs, name)
+	r.engines[name] = engine
+	return nil
+}
+
+//SetDefault makes name the data source of requests with an empty DataSource
+func (r *Registry) SetDefault(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.known(name) {
+		return fmt.Errorf("The data source %s is not configured", name)
+	}
+	r.defaultSource = name
+	return nil
+}
+
+//DataSources returns the configured data source names, sorted
+func (r *Registry) DataSources() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := []string{}
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	for name := range r.engines {
+		if _, ok := r.configs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Engine returns the engine of dataSource, building it on first use. An empty dataSource is the
+//default one. A failed build is not cached, the next call tries again.
+func (r *Registry) Engine(dataSource string) (*AthenaEngine, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := dataSource
+	if name == "" {
+		if r.defaultSource == "" {
+			return nil, fmt.Errorf("The request has no DataSource and the registry has no default")
+		}
+		name = r.defaultSource
+	}
+	if engine, ok := r.engines[name]; ok {
+		return engine, nil
+	}
+	config, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("The data source %s is not configured", name)
+	}
+	engine, err := r.newEngine(config)
+	if err != nil {
+		return nil, fmt.Errorf("The data source %s is not usable: %v", name, err)
+	}
+	r.engines[name] = engine
+	return engine, nil
+}
+
+//Exec routes the request to the engine of its DataSource
+func (r *Registry) Exec(param *RequestParam) (*ResponseData, error) {
+	return r.ExecContext(context.Background(), param)
+}
+
+//ExecContext routes the request to the engine of its DataSource with a context
+func (r *Registry) ExecContext(ctx context.Context, param *RequestParam) (*ResponseData, error) {
+	if param == nil {
+		return nil, nil
+	}
+	engine, err := r.Engine(param.DataSource)
+	if err != nil {
+		return nil, err
+	}
+	return engine.ExecContext(ctx, param)
+}
+
+func (r *Registry) known(name string) bool {
+	if _, ok := r.configs[name]; ok {
+		return true
+	}
+	_, ok := r.engines[name]
+	return ok
+}
@@ -0,0 +1,156 @@
+package athena
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewRegistry(t *testing.T) {
+	sales := &Config{Region: "us-east-1"}
+	ops := &Config{Region: "eu-west-1"}
+	tests := []struct {
+		name          string
+		configs       map[string]*Config
+		defaultSource string
+		wantDefault   string
+		wantErr       bool
+	}{
+		{name: "t-explicit", configs: map[string]*Config{"sales": sales, "ops": ops}, defaultSource: "ops", wantDefault: "ops"},
+		{name: "t-default-name", configs: map[string]*Config{DefaultDataSource: sales, "ops": ops}, wantDefault: DefaultDataSource},
+		{name: "t-only-one", configs: map[string]*Config{"sales": sales}, wantDefault: "sales"},
+		{name: "t-none", configs: map[string]*Config{"sales": sales, "ops": ops}},
+		{name: "t-unknown-default", configs: map[string]*Config{"sales": sales}, defaultSource: "ops", wantErr: true},
+		{name: "t-nil-config", configs: map[string]*Config{"sales": nil}, wantErr: true},
+		{name: "t-empty-name", configs: map[string]*Config{"": sales}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistry(tt.configs, tt.defaultSource)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRegistry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.defaultSource != tt.wantDefault {
+				t.Errorf("NewRegistry() default = %v, want %v", got.defaultSource, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestRegistry_Engine(t *testing.T) {
+	r, err := NewRegistry(map[string]*Config{"sales": {Region: "us-east-1"}, "broken": {Region: "us-east-1"}, "ops": {Region: "eu-west-1"}}, "sales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builds := map[string]int{}
+	r.newEngine = func(config *Config) (*AthenaEngine, error) {
+		builds[config.Region]++
+		if config.PollStrategy == "linear" {
+			return nil, fmt.Errorf("mock build error")
+		}
+		return &AthenaEngine{athena: &MockAthenaClient{}, WorkGroup: config.Region}, nil
+	}
+	if err := r.Register("broken", &Config{Region: "us-west-2", PollStrategy: "linear"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		dataSource    string
+		wantWorkGroup string
+		wantErr       bool
+	}{
+		{name: "t-default", wantWorkGroup: "us-east-1"},
+		{name: "t-named", dataSource: "ops", wantWorkGroup: "eu-west-1"},
+		{name: "t-cached", dataSource: "sales", wantWorkGroup: "us-east-1"},
+		{name: "t-unknown", dataSource: "hr", wantErr: true},
+		{name: "t-build-error", dataSource: "broken", wantErr: true},
+		{name: "t-build-error-retried", dataSource: "broken", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Engine(tt.dataSource)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Engine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.WorkGroup != tt.wantWorkGroup {
+				t.Errorf("Registry.Engine() workgroup = %v, want %v", got.WorkGroup, tt.wantWorkGroup)
+			}
+		})
+	}
+	if want := map[string]int{"us-east-1": 1, "eu-west-1": 1, "us-west-2": 2}; !reflect.DeepEqual(builds, want) {
+		t.Errorf("Registry.Engine() builds = %v, want %v", builds, want)
+	}
+	if got, want := r.DataSources(), []string{"broken", "ops", "sales"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Registry.DataSources() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Exec(t *testing.T) {
+	r, err := NewRegistry(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterEngine("sales", &AthenaEngine{athena: &MockAthenaClient{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterEngine("ops", &AthenaEngine{athena: &MockAthenaClientFail{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		param   *RequestParam
+		want    *ResponseData
+		wantErr bool
+	}{
+		{name: "t-nil", param: nil, want: nil},
+		{name: "t-no-default", param: &RequestParam{QueryOpt: QueryOptStart}, wantErr: true},
+		{name: "t-sales", param: &RequestParam{DataSource: "sales", QueryOpt: QueryOptStart}, want: &ResponseData{QueryID: "12345-12345"}},
+		{name: "t-ops", param: &RequestParam{DataSource: "ops", QueryOpt: QueryOptStart}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Exec(tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Registry.Exec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Registry.Exec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := r.SetDefault("hr"); err == nil {
+		t.Errorf("Registry.SetDefault() of an unknown data source should fail")
+	}
+	if err := r.SetDefault("sales"); err != nil {
+		t.Errorf("Registry.SetDefault() error = %v", err)
+	}
+	if got, err := r.Exec(&RequestParam{QueryOpt: QueryOptStart}); err != nil || got.QueryID != "12345-12345" {
+		t.Errorf("Registry.Exec() with default = %v, %v", got, err)
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	r, err := LoadRegistry("", DataSourceMap{
+		DefaultDataSource: {"region": "us-east-1", "output_location": "s3://default"},
+		"ops":             {"region": "eu-west-1", "workgroup": "ops"},
+	})
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+	if r.defaultSource != DefaultDataSource {
+		t.Errorf("LoadRegistry() default = %v", r.defaultSource)
+	}
+	engine, err := r.Engine("ops")
+	if err != nil || engine.WorkGroup != "ops" {
+		t.Errorf("LoadRegistry() engine = %v, %v", engine, err)
+	}
+	if _, err := LoadRegistry("", DataSourceMap{"ops": {"region": "eu-west-1"}}); err == nil {
+		t.Errorf("LoadRegistry() with an invalid config should fail")
+	}
+}
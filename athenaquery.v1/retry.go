@@ -0,0 +1,119 @@
+package athena
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+//DefaultRetryBackoff is the first wait before a re-submission when the config sets none
+const DefaultRetryBackoff = time.Second
+
+//retryableReasons are StateChangeReason fragments Athena uses for transient failures
+var retryableReasons = []string{
+	"query exhausted resources",
+	"slow down",
+	"slowdown",
+	"throttl",
+	"rate exceeded",
+	"internal error",
+	"internal_error",
+}
+
+//RetryPolicy makes QueryResult re-submit a query whose failure is classified as retryable
+type RetryPolicy struct {
+	//MaxAttempts counts the first submission too, values below 2 disable retries
+	MaxAttempts int
+	//Backoff decides the wait before each re-submission, its attempt 0 is the first retry
+	Backoff PollStrategy
+	//Retryable replaces IsRetryableError as the classifier when set
+	Retryable func(error) bool
+	//OnAttempt is called after every attempt with its outcome
+	OnAttempt func(QueryAttempt)
+}
+
+//QueryAttempt describes one submission made by QueryResult
+type QueryAttempt struct {
+	//Attempt starts at 1
+	Attempt   int
+	QueryID   string
+	Err       error
+	WillRetry bool
+}
+
+//IsRetryableError reports whether err is a query failure worth re-submitting: Athena marked it
+//retryable, classified it as a system error, or gave a reason known to be transient
+func IsRetryableError(err error) bool {
+	var failed *QueryFailedError
+	if !errors.As(err, &failed) {
+		return false
+	}
+	if failed.Retryable || failed.ErrorCategory == ErrorCategorySystem {
+		return true
+	}
+	reason := strings.ToLower(failed.Reason + " " + failed.ErrorMessage)
+	for _, fragment := range retryableReasons {
+		if strings.Contains(reason, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p != nil && p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	if p == nil || p.Backoff == nil {
+		return DefaultRetryBackoff
+	}
+	return p.Backoff.Delay(retry)
+}
+
+func (p *RetryPolicy) observe(attempt QueryAttempt) {
+	if p != nil && p.OnAttempt != nil {
+		p.OnAttempt(attempt)
+	}
+}
+
+//runQuery submits the query and waits for it, re-submitting retryable failures per c.Retry.
+//Each attempt gets its own MaxTimeout. The query IDs are returned in submission order and the
+//progress belongs to the last one.
+func (c *AthenaEngine) runQuery(ctx context.Context, qi *RequestParam) (*queryProgress, []string, error) {
+	attempted := []string{}
+	for attempt := 1; ; attempt++ {
+		submitted := time.Now()
+		queryID, err := c.startQuery(ctx, qi, attempt)
+		if err != nil {
+			return nil, attempted, err
+		}
+		attempted = append(attempted, queryID)
+
+		progress, err := c.waitQueryToFinish(ctx, queryID, submitted)
+		willRetry := err != nil && attempt < c.Retry.maxAttempts() && ctx.Err() == nil && c.Retry.retryable(err)
+		c.Retry.observe(QueryAttempt{Attempt: attempt, QueryID: queryID, Err: err, WillRetry: willRetry})
+		if !willRetry {
+			return progress, attempted, err
+		}
+
+		timer := time.NewTimer(c.Retry.delay(attempt - 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return progress, attempted, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+//MockAthenaClientRetry fails the first failures queries with the given status, then succeeds.
+//Like Athena, a start with a ClientRequestToken seen before returns the same query.
+type MockAthenaClientRetry struct {
+	athenaiface.AthenaAPI
+	failures int
+	status   *athena.QueryExecutionStatus
+	starts   int
+	tokens   map[string]string
+}
+
+func (m *MockAthenaClientRetry) StartQueryExecutionWithContext(ctx aws.Context, input *athena.StartQueryExecutionInput, opts ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	token := aws.StringValue(input.ClientRequestToken)
+	if queryID, ok := m.tokens[token]; ok {
+		return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String(queryID)}, nil
+	}
+	m.starts++
+	queryID := fmt.Sprintf("q-%d", m.starts)
+	if token != "" {
+		if m.tokens == nil {
+			m.tokens = map[string]string{}
+		}
+		m.tokens[token] = queryID
+	}
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String(queryID)}, nil
+}
+
+func (m *MockAthenaClientRetry) GetQueryExecutionWithContext(ctx aws.Context, input *athena.GetQueryExecutionInput, opts ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	var n int
+	fmt.Sscanf(aws.StringValue(input.QueryExecutionId), "q-%d", &n)
+	status := &athena.QueryExecutionStatus{State: aws.String(athena.QueryExecutionStateSucceeded)}
+	if n <= m.failures {
+		status = m.status
+	}
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{QueryExecutionId: input.QueryExecutionId, Status: status}}, nil
+}
+
+func (m *MockAthenaClientRetry) GetQueryResultsWithContext(ctx aws.Context, input *athena.GetQueryResultsInput, opts ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	return &athena.GetQueryResultsOutput{ResultSet: &athena.ResultSet{ResultSetMetadata: &athena.ResultSetMetadata{}}}, nil
+}
+
+var retryableStatus = &athena.QueryExecutionStatus{
+	State:             aws.String(athena.QueryExecutionStateFailed),
+	StateChangeReason: aws.String("Query exhausted resources at this scale factor"),
+}
+
+var userErrorStatus = &athena.QueryExecutionStatus{
+	State:             aws.String(athena.QueryExecutionStateFailed),
+	StateChangeReason: aws.String("SYNTAX_ERROR: line 1:8: Column 'x' cannot be resolved"),
+	AthenaError:       &athena.AthenaError{ErrorCategory: aws.Int64(ErrorCategoryUser), Retryable: aws.Bool(false)},
+}
+
+func TestAthenaEngine_QueryResultRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		status        *athena.QueryExecutionStatus
+		maxAttempts   int
+		wantErr       bool
+		wantAttempted []string
+		wantStatus    string
+		wantObserved  []bool
+	}{
+		{name: "t-no-policy", failures: 1, status: retryableStatus, maxAttempts: 0, wantErr: true,
+			wantAttempted: []string{"q-1"}, wantStatus: athena.QueryExecutionStateFailed},
+		{name: "t-retry-succeeds", failures: 2, status: retryableStatus, maxAttempts: 3,
+			wantAttempted: []string{"q-1", "q-2", "q-3"}, wantStatus: athena.QueryExecutionStateSucceeded, wantObserved: []bool{true, true, false}},
+		{name: "t-attempts-exhausted", failures: 3, status: retryableStatus, maxAttempts: 2, wantErr: true,
+			wantAttempted: []string{"q-1", "q-2"}, wantStatus: athena.QueryExecutionStateFailed, wantObserved: []bool{true, false}},
+		{name: "t-not-retryable", failures: 1, status: userErrorStatus, maxAttempts: 3, wantErr: true,
+			wantAttempted: []string{"q-1"}, wantStatus: athena.QueryExecutionStateFailed, wantObserved: []bool{false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observed := []bool{}
+			c := &AthenaEngine{
+				athena: &MockAthenaClientRetry{failures: tt.failures, status: tt.status},
+				Poll:   FixedPoll{Interval: time.Millisecond},
+			}
+			if tt.maxAttempts > 0 {
+				c.Retry = &RetryPolicy{
+					MaxAttempts: tt.maxAttempts,
+					Backoff:     FixedPoll{Interval: time.Millisecond},
+					OnAttempt:   func(a QueryAttempt) { observed = append(observed, a.WillRetry) },
+				}
+			}
+			got, err := c.QueryResultContext(context.Background(), &RequestParam{SQL: "SELECT 1"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AthenaEngine.QueryResultContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got == nil {
+				t.Fatalf("AthenaEngine.QueryResultContext() = nil, want the attempts")
+			}
+			if !reflect.DeepEqual(got.AttemptedQueryIDs, tt.wantAttempted) {
+				t.Errorf("AthenaEngine.QueryResultContext() attempted = %v, want %v", got.AttemptedQueryIDs, tt.wantAttempted)
+			}
+			if got.QueryID != tt.wantAttempted[len(tt.wantAttempted)-1] {
+				t.Errorf("AthenaEngine.QueryResultContext() query id = %v", got.QueryID)
+			}
+			if got.QueryStatus != tt.wantStatus {
+				t.Errorf("AthenaEngine.QueryResultContext() status = %v, want %v", got.QueryStatus, tt.wantStatus)
+			}
+			if tt.wantObserved != nil && !reflect.DeepEqual(observed, tt.wantObserved) {
+				t.Errorf("AthenaEngine.QueryResultContext() observed = %v, want %v", observed, tt.wantObserved)
+			}
+		})
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "t-nil", err: nil, want: false},
+		{name: "t-other-error", err: fmt.Errorf("boom"), want: false},
+		{name: "t-timeout", err: &QueryTimeoutError{QueryID: "q"}, want: false},
+		{name: "t-flagged", err: &QueryFailedError{Retryable: true}, want: true},
+		{name: "t-system", err: &QueryFailedError{ErrorCategory: ErrorCategorySystem}, want: true},
+		{name: "t-user", err: &QueryFailedError{ErrorCategory: ErrorCategoryUser, Reason: "SYNTAX_ERROR"}, want: false},
+		{name: "t-exhausted", err: &QueryFailedError{Reason: "Query exhausted resources at this scale factor"}, want: true},
+		{name: "t-s3-slowdown", err: &QueryFailedError{Reason: "HIVE_CANNOT_OPEN_SPLIT: Please reduce your request rate. (Service: Amazon S3; Status Code: 503; Error Code: SlowDown)"}, want: true},
+		{name: "t-wrapped", err: fmt.Errorf("wrapped: %w", &QueryFailedError{Retryable: true}), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAthenaEngine_setupRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantMax time.Duration
+	}{
+		{name: "t-max-interval", config: &Config{RetryMaxAttempts: 3, MaxInterval: 10}, wantMax: 10 * time.Second},
+		{name: "t-default-max", config: &Config{RetryMaxAttempts: 3}, wantMax: DefaultMaxInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AthenaEngine{}
+			if err := c.setupRetry(tt.config); err != nil {
+				t.Fatalf("AthenaEngine.setupRetry() error = %v", err)
+			}
+			if got := c.Retry.Backoff.(ExponentialPoll).Max; got != tt.wantMax {
+				t.Errorf("AthenaEngine.setupRetry() Max = %v, want %v", got, tt.wantMax)
+			}
+		})
+	}
+}
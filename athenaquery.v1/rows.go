@@ -0,0 +1,187 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+)
+
+//Rows is a forward-only iterator over the result of a finished query.
+//Pages are fetched lazily via NextToken, so only one page is held in memory at a time.
+//The header row of a SELECT result is not yielded, see Header.
+type Rows struct {
+	ctx      context.Context
+	athena   athenaiface.AthenaAPI
+	queryID  string
+	pageSize int
+
+	columns   []*athena.ColumnInfo
+	header    *athena.Row
+	page      []*athena.Row
+	pos       int
+	current   *athena.Row
+	nextToken *string
+	fetched   bool
+	closed    bool
+	err       error
+}
+
+//Rows opens an iterator over the result of queryID, the query must already be finished
+func (c *AthenaEngine) Rows(ctx context.Context, queryID string) *Rows {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Rows{
+		ctx:      ctx,
+		athena:   c.athena,
+		queryID:  queryID,
+		pageSize: c.PageSize,
+	}
+}
+
+//QueryID returns the query the iterator reads from
+func (r *Rows) QueryID() string {
+	return r.queryID
+}
+
+//Next advances to the next row, fetching the next page when the current one is used up.
+//It returns false at the end of the result set or on error; check Err to tell them apart.
+func (r *Rows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+	for r.pos >= len(r.page) {
+		if r.fetched && r.nextToken == nil {
+			r.current = nil
+			return false
+		}
+		if err := r.fetch(); err != nil {
+			r.err, r.current = err, nil
+			return false
+		}
+	}
+	r.current = r.page[r.pos]
+	r.pos++
+	return true
+}
+
+//Columns returns the column metadata, fetching the first page if needed
+func (r *Rows) Columns() ([]*athena.ColumnInfo, error) {
+	if r.closed {
+		return nil, fmt.Errorf("The Athena Rows of query %s is closed", r.queryID)
+	}
+	if !r.fetched && r.err == nil {
+		if err := r.fetch(); err != nil {
+			r.err = err
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.columns, nil
+}
+
+//Header returns the header row of a SELECT result, nil before the first page is fetched
+//or when the statement has none
+func (r *Rows) Header() *athena.Row {
+	return r.header
+}
+
+//Row returns the current raw row, nil before the first Next or after the last one
+func (r *Rows) Row() *athena.Row {
+	return r.current
+}
+
+//Scan copies the current row into dest. Each dest must be a *string (NULL becomes ""),
+//a **string (NULL becomes nil) or an *interface{} (NULL becomes nil, otherwise a string).
+func (r *Rows) Scan(dest ...interface{}) error {
+	if r.current == nil {
+		return fmt.Errorf("The Athena Rows Scan is called without a current row")
+	}
+	if len(dest) != len(r.current.Data) {
+		return fmt.Errorf("The Athena Rows Scan expects %d destinations, got %d", len(r.current.Data), len(dest))
+	}
+	for i, d := range dest {
+		var value *string
+		if r.current.Data[i] != nil {
+			value = r.current.Data[i].VarCharValue
+		}
+		switch p := d.(type) {
+		case *string:
+			*p = aws.StringValue(value)
+		case **string:
+			*p = value
+		case *interface{}:
+			if value == nil {
+				*p = nil
+			} else {
+				*p = *value
+			}
+		default:
+			return fmt.Errorf("The Athena Rows Scan does not support destination %d of type %T", i, d)
+		}
+	}
+	return nil
+}
+
+//Err returns the error that stopped the iteration, if any
+func (r *Rows) Err() error {
+	return r.err
+}
+
+//Close releases the buffered page; further calls to Next return false
+func (r *Rows) Close() error {
+	r.closed = true
+	r.page, r.current = nil, nil
+	return nil
+}
+
+func (r *Rows) fetch() error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	input := &athena.GetQueryResultsInput{
+		QueryExecutionId: aws.String(r.queryID),
+		NextToken:        r.nextToken,
+	}
+	pageSize := r.pageSize
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	if pageSize > 0 {
+		input.MaxResults = aws.Int64(int64(pageSize))
+	}
+
+	skipHeader := false
+	if !r.fetched {
+		qe, err := r.athena.GetQueryExecutionWithContext(r.ctx, &athena.GetQueryExecutionInput{QueryExecutionId: aws.String(r.queryID)})
+		if err != nil {
+			return err
+		}
+		skipHeader = hasHeaderRow(qe.QueryExecution)
+	}
+
+	out, err := r.athena.GetQueryResultsWithContext(r.ctx, input)
+	if err != nil {
+		return err
+	}
+
+	r.page, r.pos, r.nextToken = nil, 0, nil
+	if out.ResultSet != nil {
+		if !r.fetched && out.ResultSet.ResultSetMetadata != nil {
+			r.columns = out.ResultSet.ResultSetMetadata.ColumnInfo
+		}
+		r.page = out.ResultSet.Rows
+		if skipHeader && len(r.page) > 0 && isHeaderRow(r.columns, r.page[0]) {
+			r.header, r.page = r.page[0], r.page[1:]
+		}
+	}
+	if aws.StringValue(out.NextToken) != "" {
+		r.nextToken = out.NextToken
+	}
+	r.fetched = true
+	return nil
+}
@@ -0,0 +1,126 @@
+package athena
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestAthenaEngine_Rows(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         []string
+		header        bool
+		statementType string
+		want          []string
+		wantHeader    bool
+		wantCalls     int
+	}{
+		{name: "t-single-page", pages: []string{"a"}, want: []string{"a"}, wantCalls: 1},
+		{name: "t-many-pages", pages: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}, wantCalls: 3},
+		{name: "t-select", pages: []string{"a", "b"}, header: true, statementType: athena.StatementTypeDml, want: []string{"a", "b"}, wantHeader: true, wantCalls: 2},
+		{name: "t-select-empty", header: true, statementType: athena.StatementTypeDml, want: []string{}, wantHeader: true, wantCalls: 1},
+		{name: "t-ddl", pages: []string{"a"}, header: true, statementType: athena.StatementTypeDdl, want: []string{"id", "a"}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockAthenaClientPaged{pages: tt.pages, header: tt.header, statementType: tt.statementType}
+			c := &AthenaEngine{athena: mock}
+
+			rows := c.Rows(context.Background(), "1234-1234")
+			defer rows.Close()
+
+			got := []string{}
+			for rows.Next() {
+				var v string
+				if err := rows.Scan(&v); err != nil {
+					t.Errorf("Rows.Scan() error = %v", err)
+					return
+				}
+				got = append(got, v)
+			}
+			if err := rows.Err(); err != nil {
+				t.Errorf("Rows.Err() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rows = %v, want %v", got, tt.want)
+			}
+			if (rows.Header() != nil) != tt.wantHeader {
+				t.Errorf("Rows.Header() = %v, wantHeader %v", rows.Header(), tt.wantHeader)
+			}
+			if mock.calls != tt.wantCalls {
+				t.Errorf("Rows calls = %v, want %v", mock.calls, tt.wantCalls)
+			}
+			if rows.Next() {
+				t.Errorf("Rows.Next() after the end should be false")
+			}
+		})
+	}
+}
+
+func TestRows_Columns(t *testing.T) {
+	mock := &MockAthenaClientPaged{pages: []string{"a", "b"}}
+	rows := (&AthenaEngine{athena: mock}).Rows(context.Background(), "1234-1234")
+
+	cols, err := rows.Columns()
+	if err != nil || len(cols) != 1 || aws.StringValue(cols[0].Name) != "id" {
+		t.Errorf("Rows.Columns() = %v, %v", cols, err)
+	}
+	if !rows.Next() || mock.calls != 1 {
+		t.Errorf("Rows.Next() should reuse the page fetched by Columns(), calls = %v", mock.calls)
+	}
+}
+
+func TestRows_Scan(t *testing.T) {
+	row := &athena.Row{Data: []*athena.Datum{
+		&athena.Datum{VarCharValue: aws.String("x")},
+		&athena.Datum{},
+		&athena.Datum{},
+	}}
+	tests := []struct {
+		name    string
+		current *athena.Row
+		dest    func() []interface{}
+		wantErr bool
+	}{
+		{name: "t-no-row", dest: func() []interface{} { return nil }, wantErr: true},
+		{name: "t-count-mismatch", current: row, dest: func() []interface{} { var s string; return []interface{}{&s} }, wantErr: true},
+		{name: "t-unsupported", current: row, dest: func() []interface{} { var i int; var s string; return []interface{}{&i, &s, &s} }, wantErr: true},
+		{name: "t-ok", current: row, dest: func() []interface{} {
+			var s string
+			var p *string
+			var i interface{}
+			return []interface{}{&s, &p, &i}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Rows{current: tt.current}
+			dest := tt.dest()
+			if err := r.Scan(dest...); (err != nil) != tt.wantErr {
+				t.Errorf("Rows.Scan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				if *dest[0].(*string) != "x" || *dest[1].(**string) != nil || *dest[2].(*interface{}) != nil {
+					t.Errorf("Rows.Scan() = %v", dest)
+				}
+			}
+		})
+	}
+}
+
+func TestRows_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows := (&AthenaEngine{athena: &MockAthenaClientPaged{pages: []string{"a"}}}).Rows(ctx, "1234-1234")
+	if rows.Next() {
+		t.Errorf("Rows.Next() should be false for a cancelled context")
+	}
+	if rows.Err() != context.Canceled {
+		t.Errorf("Rows.Err() = %v, want %v", rows.Err(), context.Canceled)
+	}
+}
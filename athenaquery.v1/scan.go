@@ -0,0 +1,306 @@
+package athena
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+//scanTag is the struct tag naming the column of a field, "-" skips the field
+const scanTag = "athena"
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	ratType     = reflect.TypeOf(big.Rat{})
+)
+
+//ScanInto stores the result rows into dest, a pointer to a slice of structs or of struct pointers.
+//See Rows.ScanInto for how columns map to fields.
+func (r *ResponseData) ScanInto(dest interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("The ScanInto destination must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("The ScanInto destination must be a slice of structs, got %T", dest)
+	}
+	fields, err := structFields(structType, r.Columns)
+	if err != nil {
+		return err
+	}
+
+	rows := r.Rows
+	decoder := &Decoder{}
+	out := reflect.MakeSlice(slice.Type(), 0, len(rows))
+	for i, row := range rows {
+		item := reflect.New(structType)
+		if err := scanRow(decoder, r.Columns, row, fields, item.Elem()); err != nil {
+			return fmt.Errorf("The row %d: %v", i, err)
+		}
+		if elemType.Kind() != reflect.Ptr {
+			item = item.Elem()
+		}
+		out = reflect.Append(out, item)
+	}
+	slice.Set(out)
+	return nil
+}
+
+//ScanInto stores the current row into dest, a pointer to a struct. A column goes to the field
+//tagged `athena:"column"`, or else to the field whose name matches the column case-insensitively,
+//ignoring underscores. Fields of embedded structs are matched too, columns without a field are
+//skipped. Values are decoded by Decoder and converted to the field type; a NULL needs a pointer,
+//interface, slice, map or sql.Scanner field.
+func (r *Rows) ScanInto(dest interface{}) error {
+	if r.current == nil {
+		return fmt.Errorf("The Athena Rows ScanInto is called without a current row")
+	}
+	item := reflect.ValueOf(dest)
+	if item.Kind() != reflect.Ptr || item.IsNil() || item.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("The ScanInto destination must be a pointer to a struct, got %T", dest)
+	}
+	columns, err := r.Columns()
+	if err != nil {
+		return err
+	}
+	fields, err := structFields(item.Elem().Type(), columns)
+	if err != nil {
+		return err
+	}
+	return scanRow(&Decoder{}, columns, r.current, fields, item.Elem())
+}
+
+//structFields returns, for each column, the index path of its field in t or nil when no field matches
+func structFields(t reflect.Type, columns []*athena.ColumnInfo) ([][]int, error) {
+	tagged, named := map[string][]int{}, map[string][]int{}
+	collectFields(t, nil, tagged, named)
+
+	fields := make([][]int, len(columns))
+	used := map[string]bool{}
+	for i, column := range columns {
+		name := aws.StringValue(column.Name)
+		if index, ok := tagged[name]; ok {
+			fields[i], used[name] = index, true
+		} else if index, ok := named[fieldKey(name)]; ok {
+			fields[i] = index
+		}
+	}
+	for tag := range tagged {
+		if !used[tag] {
+			return nil, fmt.Errorf("The field tagged %s:%q of %s has no column in the result", scanTag, tag, t)
+		}
+	}
+	return fields, nil
+}
+
+//collectFields walks the exported fields of t, descending into embedded structs. Shallower
+//fields win over the ones of embedded structs, like Go's own field selection.
+func collectFields(t reflect.Type, parent []int, tagged, named map[string][]int) {
+	embedded := [][]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		tag := f.Tag.Get(scanTag)
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			//a nil pointer to an unexported struct can't be allocated, like encoding/json skip it
+			if f.PkgPath == "" || f.Type.Kind() != reflect.Ptr {
+				embedded = append(embedded, index)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if tag != "" {
+			if _, ok := tagged[tag]; !ok {
+				tagged[tag] = index
+			}
+			continue
+		}
+		if _, ok := named[fieldKey(f.Name)]; !ok {
+			named[fieldKey(f.Name)] = index
+		}
+	}
+	for _, index := range embedded {
+		ft := t.FieldByIndex(index[len(parent):]).Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		collectFields(ft, index, tagged, named)
+	}
+}
+
+//fieldKey folds a field or column name for matching, "order_id" and "OrderID" are the same
+func fieldKey(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+func scanRow(decoder *Decoder, columns []*athena.ColumnInfo, row *athena.Row, fields [][]int, item reflect.Value) error {
+	if row == nil || len(row.Data) != len(columns) {
+		return fmt.Errorf("The row does not match the %d columns", len(columns))
+	}
+	for i, index := range fields {
+		if index == nil {
+			continue
+		}
+		column := columns[i]
+		value, err := decoder.Decode(column, row.Data[i])
+		if err != nil {
+			return fmt.Errorf("The column %s: %v", aws.StringValue(column.Name), err)
+		}
+		var raw *string
+		if row.Data[i] != nil {
+			raw = row.Data[i].VarCharValue
+		}
+		field := fieldByIndex(item, index)
+		if err := setField(field, value, raw); err != nil {
+			return fmt.Errorf("The column %s (%s) can't be stored in field %s (%s): %v",
+				aws.StringValue(column.Name), aws.StringValue(column.Type), item.Type().FieldByIndex(index).Name, field.Type(), err)
+		}
+	}
+	return nil
+}
+
+//fieldByIndex is reflect.Value.FieldByIndex allocating the nil embedded struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+//setField converts the decoded value, with raw the text Athena returned, to the type of field
+func setField(field reflect.Value, value interface{}, raw *string) error {
+	if field.CanAddr() && field.Addr().Type().Implements(scannerType) {
+		return field.Addr().Interface().(sql.Scanner).Scan(scannerValue(value))
+	}
+	if value == nil {
+		switch field.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		return fmt.Errorf("the value is NULL, use a pointer field")
+	}
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value, raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case field.Type() == ratType:
+		r, ok := new(big.Rat).SetString(*raw)
+		if !ok {
+			return fmt.Errorf("the value %q is not a number", *raw)
+		}
+		field.Set(reflect.ValueOf(*r))
+		return nil
+	case field.Type() == timeType:
+		if t, ok := value.(time.Time); ok {
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return fmt.Errorf("the value %q is not a date or timestamp", *raw)
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(*raw)
+		return nil
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			field.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(*raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("the value %q is not an integer", *raw)
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("the value %d overflows the field", n)
+		}
+		field.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(*raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("the value %q is not an unsigned integer", *raw)
+		}
+		if field.OverflowUint(n) {
+			return fmt.Errorf("the value %d overflows the field", n)
+		}
+		field.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := parseDouble("double", *raw)
+		if err != nil {
+			return fmt.Errorf("the value %q is not a number", *raw)
+		}
+		if field.OverflowFloat(f) {
+			return fmt.Errorf("the value %v overflows the field", f)
+		}
+		field.SetFloat(f)
+		return nil
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Array:
+		if data, ok := value.(json.RawMessage); ok {
+			return json.Unmarshal(data, field.Addr().Interface())
+		}
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(*raw))
+			return nil
+		}
+	}
+	return fmt.Errorf("the value %T is not convertible", value)
+}
+
+//scannerValue turns a decoded value into one of the types database/sql hands to a Scanner
+func scannerValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.RawMessage:
+		return []byte(v)
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return data
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package athena
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+type scanAudit struct {
+	CreatedAt time.Time `athena:"created_at"`
+	Author    *string
+}
+
+type scanOrder struct {
+	scanAudit
+	ID       int32 `athena:"order_id"`
+	Customer string
+	Amount   float64
+	Discount *float64
+	Paid     bool
+	Note     sql.NullString
+	Tags     []string `athena:"tags"`
+	Ignored  string   `athena:"-"`
+	internal string
+}
+
+func scanRows(values ...[]*string) []*athena.Row {
+	rows := []*athena.Row{}
+	for _, row := range values {
+		data := []*athena.Datum{}
+		for _, v := range row {
+			data = append(data, &athena.Datum{VarCharValue: v})
+		}
+		rows = append(rows, &athena.Row{Data: data})
+	}
+	return rows
+}
+
+func TestResponseData_ScanInto(t *testing.T) {
+	columns := []*athena.ColumnInfo{
+		{Name: aws.String("order_id"), Type: aws.String("integer")},
+		{Name: aws.String("customer"), Type: aws.String("varchar")},
+		{Name: aws.String("AMOUNT"), Type: aws.String("decimal(10,2)")},
+		{Name: aws.String("discount"), Type: aws.String("double")},
+		{Name: aws.String("paid"), Type: aws.String("boolean")},
+		{Name: aws.String("note"), Type: aws.String("varchar")},
+		{Name: aws.String("tags"), Type: aws.String("json")},
+		{Name: aws.String("created_at"), Type: aws.String("timestamp")},
+		{Name: aws.String("author"), Type: aws.String("varchar")},
+		{Name: aws.String("extra"), Type: aws.String("varchar")},
+	}
+	header := []*string{aws.String("order_id"), aws.String("customer"), aws.String("AMOUNT"), aws.String("discount"),
+		aws.String("paid"), aws.String("note"), aws.String("tags"), aws.String("created_at"), aws.String("author"), aws.String("extra")}
+	first := []*string{aws.String("7"), aws.String("ann"), aws.String("12.30"), aws.String("0.5"),
+		aws.String("true"), aws.String("rush"), aws.String(`["a","b"]`), aws.String("2024-02-29 13:14:15.000"), aws.String("bob"), aws.String("x")}
+	second := []*string{aws.String("8"), aws.String("cy"), aws.String("1"), nil,
+		aws.String("false"), nil, nil, aws.String("2024-03-01 00:00:00.000"), nil, nil}
+	created := time.Date(2024, 2, 29, 13, 14, 15, 0, time.UTC)
+
+	data := &ResponseData{Columns: columns, Header: scanRows(header)[0], Rows: scanRows(first, second)}
+	var got []scanOrder
+	if err := data.ScanInto(&got); err != nil {
+		t.Fatalf("ResponseData.ScanInto() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ResponseData.ScanInto() = %d rows, want 2", len(got))
+	}
+	o := got[0]
+	if o.ID != 7 || o.Customer != "ann" || o.Amount != 12.3 || o.Discount == nil || *o.Discount != 0.5 || !o.Paid ||
+		o.Note != (sql.NullString{String: "rush", Valid: true}) || !reflect.DeepEqual(o.Tags, []string{"a", "b"}) ||
+		!o.CreatedAt.Equal(created) || o.Author == nil || *o.Author != "bob" {
+		t.Errorf("ResponseData.ScanInto() first = %+v", o)
+	}
+	o = got[1]
+	if o.ID != 8 || o.Discount != nil || o.Paid || o.Note.Valid || o.Tags != nil || o.Author != nil {
+		t.Errorf("ResponseData.ScanInto() second = %+v", o)
+	}
+
+	var pointers []*scanOrder
+	if err := data.ScanInto(&pointers); err != nil || len(pointers) != 2 || pointers[1].Customer != "cy" {
+		t.Errorf("ResponseData.ScanInto() into pointers = %v, %v", pointers, err)
+	}
+}
+
+func TestResponseData_ScanIntoErrors(t *testing.T) {
+	type plain struct {
+		ID int8 `athena:"id"`
+	}
+	type missing struct {
+		ID   int64  `athena:"id"`
+		Name string `athena:"name"`
+	}
+	type mismatch struct {
+		ID time.Time `athena:"id"`
+	}
+	type raw struct {
+		ID map[string]int `athena:"id"`
+	}
+	data := func(values ...*string) *ResponseData {
+		rows := [][]*string{}
+		for _, v := range values {
+			rows = append(rows, []*string{v})
+		}
+		return &ResponseData{Columns: []*athena.ColumnInfo{{Name: aws.String("id"), Type: aws.String("bigint")}}, Rows: scanRows(rows...)}
+	}
+	tests := []struct {
+		name    string
+		data    *ResponseData
+		dest    interface{}
+		wantErr bool
+	}{
+		{name: "t-not-pointer", data: data(), dest: []plain{}, wantErr: true},
+		{name: "t-not-struct", data: data(), dest: &[]string{}, wantErr: true},
+		{name: "t-ok", data: data(aws.String("1")), dest: &[]plain{}},
+		{name: "t-null", data: data(nil), dest: &[]plain{}, wantErr: true},
+		{name: "t-overflow", data: data(aws.String("300")), dest: &[]plain{}, wantErr: true},
+		{name: "t-bad-value", data: data(aws.String("x")), dest: &[]plain{}, wantErr: true},
+		{name: "t-missing-column", data: data(aws.String("1")), dest: &[]missing{}, wantErr: true},
+		{name: "t-type-mismatch", data: data(aws.String("1")), dest: &[]mismatch{}, wantErr: true},
+		{name: "t-not-convertible", data: data(aws.String("1")), dest: &[]raw{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.data.ScanInto(tt.dest); (err != nil) != tt.wantErr {
+				t.Errorf("ResponseData.ScanInto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRows_ScanInto(t *testing.T) {
+	type item struct {
+		ID   int64
+		Name *string `athena:"name"`
+	}
+	r := &Rows{
+		fetched: true,
+		columns: []*athena.ColumnInfo{{Name: aws.String("id"), Type: aws.String("bigint")}, {Name: aws.String("name"), Type: aws.String("varchar")}},
+	}
+	var got item
+	if err := r.ScanInto(&got); err == nil {
+		t.Errorf("Rows.ScanInto() without a current row should fail")
+	}
+	r.current = scanRows([]*string{aws.String("42"), nil})[0]
+	if err := r.ScanInto(got); err == nil {
+		t.Errorf("Rows.ScanInto() into a non-pointer should fail")
+	}
+	if err := r.ScanInto(&got); err != nil || got.ID != 42 || got.Name != nil {
+		t.Errorf("Rows.ScanInto() = %+v, %v", got, err)
+	}
+}